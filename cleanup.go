@@ -9,16 +9,16 @@ import (
 	"crypto/md5"    // Implements the MD5 hash algorithm, an option for finding duplicates.
 	"crypto/sha1"   // Implements the SHA-1 hash algorithm, an option for finding duplicates.
 	"crypto/sha256" // Implements the SHA-256 hash algorithm, the default for finding duplicates.
-	"encoding/csv"  // For writing output in CSV format when requested by the user.
 	"encoding/json" // For writing output in JSON format when requested by the user.
 	"errors"        // For creating and inspecting standard error values.
 	"fmt"           // Provides functions for formatted I/O (like printing to the console).
 	"hash"          // Provides a common interface for cryptographic hash functions (md5, sha1, sha256).
 	"io"            // Provides basic I/O interfaces, like io.Writer for handling different output streams.
-	"log"           // Provides simple logging capabilities.
+	"log/slog"      // For logging events with fields.
 	"os"            // Provides a platform-independent interface to operating system functionality.
 	"os/signal"     // For capturing operating system signals, allowing the program to react to Ctrl+C.
 	"path/filepath" // For manipulating filesystem paths in a way that is safe across different OSes (Windows, Linux, macOS).
+	"reflect"       // For writing result records of any type.
 	"regexp"        // For regular expression matching to exclude files/folders based on patterns.
 	"runtime"       // Provides interaction with the Go runtime, used here to get the number of CPUs for parallel processing.
 	"sort"          // Provides sorting algorithms for slices.
@@ -33,6 +33,7 @@ import (
 	"github.com/schollz/progressbar/v3" // A library for displaying progress bars in the terminal.
 	"github.com/spf13/cobra"            // A powerful library for creating modern command-line applications with subcommands and flags.
 	"github.com/spf13/viper"            // A library for application configuration, handling files, environment variables, and flags.
+	"golang.org/x/term"                 // For checking whether prompts can be answered on a terminal.
 	"gopkg.in/yaml.v3"                  // A library for working with YAML files, used for the config file.
 )
 
@@ -52,27 +53,85 @@ var (
 // - `mapstructure` tags are for Viper to read keys from the config file (e.g., 'use-trash' in YAML maps to UseTrash field).
 // - `yaml` tags are for generating a clean config file with `config init` (e.g., UseTrash field is written as 'trash' in YAML).
 type Config struct {
-	Recursive       bool     `mapstructure:"recursive" yaml:"recursive"`
-	DryRun          bool     `mapstructure:"dry-run" yaml:"dry-run"`
-	Force           bool     `mapstructure:"force" yaml:"force"`
-	Verbose         bool     `mapstructure:"verbose" yaml:"verbose"`
-	Quiet           bool     `mapstructure:"quiet" yaml:"quiet"`
-	UseTrash        bool     `mapstructure:"trash" yaml:"trash"`
-	OutputFormat    string   `mapstructure:"output-format" yaml:"output-format"`
-	LogFile         string   `mapstructure:"log-file" yaml:"log-file"`
-	IgnoreFiles     []string `mapstructure:"ignore-files" yaml:"ignore-files"`
-	ExcludeDirs     []string `mapstructure:"exclude-dirs" yaml:"exclude-dirs"`
-	ExcludePattern  string   `mapstructure:"exclude-pattern" yaml:"exclude-pattern"`
-	ExcludeGlob     string   `mapstructure:"exclude-glob" yaml:"exclude-glob"`
-	ExcludeGlobPath string   `mapstructure:"exclude-glob-path" yaml:"exclude-glob-path"`
-	OlderThanStr    string   `mapstructure:"older-than" yaml:"older-than"`
-	FilesOverStr    string   `mapstructure:"files-over" yaml:"files-over"`
-	TopN            int      `mapstructure:"top-n" yaml:"top-n"`
-	DuplicateKeep   string   `mapstructure:"duplicate-keep" yaml:"duplicate-keep"`
-	FindDuplicates  bool     `mapstructure:"find-duplicates" yaml:"find-duplicates"`
-	HashAlgo        string   `mapstructure:"hash-algo" yaml:"hash-algo"`
-	SortBy          string   `mapstructure:"sort-by" yaml:"sort-by"`
-	ConfigFile      string   `mapstructure:"-" yaml:"-"` // This field is for internal use and should not be saved to or read from the config file.
+	Recursive           bool     `mapstructure:"recursive" yaml:"recursive"`
+	DryRun              bool     `mapstructure:"dry-run" yaml:"dry-run"`
+	Force               bool     `mapstructure:"force" yaml:"force"`
+	Verbose             bool     `mapstructure:"verbose" yaml:"verbose"`
+	Quiet               bool     `mapstructure:"quiet" yaml:"quiet"`
+	UseTrash            bool     `mapstructure:"trash" yaml:"trash"`
+	OutputFormat        string   `mapstructure:"output-format" yaml:"output-format"`
+	OutputFile          string   `mapstructure:"output-file" yaml:"output-file"`
+	Print0              bool     `mapstructure:"print0" yaml:"print0"`
+	Fields              []string `mapstructure:"fields" yaml:"fields"`
+	Format              string   `mapstructure:"format" yaml:"format"`
+	LogFile             string   `mapstructure:"log-file" yaml:"log-file"`
+	LogFormat           string   `mapstructure:"log-format" yaml:"log-format"`
+	LogLevel            string   `mapstructure:"log-level" yaml:"log-level"`
+	ErrorsOut           string   `mapstructure:"errors-out" yaml:"errors-out"`
+	SummaryFile         string   `mapstructure:"summary-file" yaml:"summary-file"`
+	IgnoreFiles         []string `mapstructure:"ignore-files" yaml:"ignore-files"`
+	IncludeEmptyFiles   bool     `mapstructure:"include-empty-files" yaml:"include-empty-files"`
+	JunkPreset          string   `mapstructure:"junk-preset" yaml:"junk-preset"`
+	MaxJunkSize         string   `mapstructure:"max-junk-size" yaml:"max-junk-size"`
+	EmptyFiles          bool     `mapstructure:"empty-files" yaml:"empty-files"`
+	ExcludeDirs         []string `mapstructure:"exclude-dirs" yaml:"exclude-dirs"`
+	ExcludePattern      string   `mapstructure:"exclude-pattern" yaml:"exclude-pattern"`
+	ExcludeGlob         string   `mapstructure:"exclude-glob" yaml:"exclude-glob"`
+	ExcludeGlobPath     string   `mapstructure:"exclude-glob-path" yaml:"exclude-glob-path"`
+	OlderThanStr        string   `mapstructure:"older-than" yaml:"older-than"`
+	AgeBy               string   `mapstructure:"age-by" yaml:"age-by"`
+	NewerThanStr        string   `mapstructure:"newer-than" yaml:"newer-than"`
+	ModifiedBefore      string   `mapstructure:"modified-before" yaml:"modified-before"`
+	ModifiedAfter       string   `mapstructure:"modified-after" yaml:"modified-after"`
+	FilesOverStr        string   `mapstructure:"files-over" yaml:"files-over"`
+	FilesUnderStr       string   `mapstructure:"files-under" yaml:"files-under"`
+	SizeRange           string   `mapstructure:"size" yaml:"size"`
+	MatchMode           string   `mapstructure:"match" yaml:"match"`
+	Where               string   `mapstructure:"where" yaml:"where"`
+	Owner               string   `mapstructure:"owner" yaml:"owner"`
+	Group               string   `mapstructure:"group" yaml:"group"`
+	UID                 string   `mapstructure:"uid" yaml:"uid"`
+	NoUser              bool     `mapstructure:"nouser" yaml:"nouser"`
+	Perm                string   `mapstructure:"perm" yaml:"perm"`
+	WorldWritable       bool     `mapstructure:"world-writable" yaml:"world-writable"`
+	TopN                int      `mapstructure:"top-n" yaml:"top-n"`
+	DuplicateKeep       string   `mapstructure:"duplicate-keep" yaml:"duplicate-keep"`
+	FindDuplicates      bool     `mapstructure:"find-duplicates" yaml:"find-duplicates"`
+	DuplicateDirs       bool     `mapstructure:"duplicate-dirs" yaml:"duplicate-dirs"`
+	SimilarImages       bool     `mapstructure:"similar-images" yaml:"similar-images"`
+	Decisions           string   `mapstructure:"decisions" yaml:"decisions"`
+	ExportDecisions     string   `mapstructure:"export-decisions" yaml:"export-decisions"`
+	ImageHash           string   `mapstructure:"image-hash" yaml:"image-hash"`
+	SimilarityThreshold int      `mapstructure:"similarity-threshold" yaml:"similarity-threshold"`
+	HashAlgo            string   `mapstructure:"hash-algo" yaml:"hash-algo"`
+	SortBy              string   `mapstructure:"sort-by" yaml:"sort-by"`
+	ProtectedPaths      []string `mapstructure:"protected-paths" yaml:"protected-paths"`
+	MaxDeleteCount      int      `mapstructure:"max-delete-count" yaml:"max-delete-count"`
+	MaxDeleteSize       string   `mapstructure:"max-delete-size" yaml:"max-delete-size"`
+	MaxDeletePercent    float64  `mapstructure:"max-delete-percent" yaml:"max-delete-percent"`
+	MaxErrorRate        float64  `mapstructure:"max-error-rate" yaml:"max-error-rate"`
+	IKnowWhatImDoing    bool     `mapstructure:"-" yaml:"-"` // Only settable on the command line, so no config file can switch off the safety guard.
+	ConfigFile          string   `mapstructure:"-" yaml:"-"` // This field is for internal use and should not be saved to or read from the config file.
+	Profile             string   `mapstructure:"-" yaml:"-"` // Selected via --profile only; profiles themselves live under the 'profiles' key.
+}
+
+// Profile is a named set of settings under the 'profiles' key of the config file, e.g.:
+//
+//	profiles:
+//	  downloads:
+//	    command: find
+//	    paths: [~/Downloads]
+//	    older-than: 90d
+//	  downloads-big:
+//	    inherits: downloads
+//	    files-over: 500MB
+//
+// Any key that is valid at the top level of the config file can be set in a profile.
+type Profile struct {
+	Inherits string                 `mapstructure:"inherits"` // Name of the profile this one extends.
+	Command  string                 `mapstructure:"command"`  // Subcommand to run when invoked as 'cleanup --profile NAME'.
+	Paths    []string               `mapstructure:"paths"`    // Target paths used when none are given on the command line.
+	Settings map[string]interface{} `mapstructure:",remain"`  // All other keys, applied on top of the top-level config.
 }
 
 // Global instance of the Config struct, accessible throughout the application.
@@ -85,13 +144,79 @@ type fileResult struct {
 	Info os.FileInfo
 }
 
+// fileTimes holds the timestamps of a file as far as the current platform exposes them.
+// A zero value means the timestamp is not available.
+type fileTimes struct {
+	Modified time.Time
+	Accessed time.Time
+	Changed  time.Time
+	Born     time.Time
+}
+
+// unixBirthTime converts a raw birth time from stat(2). The BSDs report -1 (or 0) if the
+// filesystem doesn't record it, which becomes the zero time as on the other platforms.
+func unixBirthTime(sec, nsec int64) time.Time {
+	if sec < 0 || (sec == 0 && nsec == 0) {
+		return time.Time{}
+	}
+	return time.Unix(sec, nsec)
+}
+
+// ageBucket is one column of the age histogram produced by the 'report' command.
+// MaxAge is the exclusive upper bound; zero marks the open-ended last bucket.
+type ageBucket struct {
+	Label  string
+	MaxAge time.Duration
+}
+
+// ageBuckets defines the histogram columns, ordered from youngest to oldest.
+var ageBuckets = []ageBucket{
+	{Label: "0-7d", MaxAge: 7 * 24 * time.Hour},
+	{Label: "7-30d", MaxAge: 30 * 24 * time.Hour},
+	{Label: "30-90d", MaxAge: 90 * 24 * time.Hour},
+	{Label: "90d-1y", MaxAge: 365 * 24 * time.Hour},
+	{Label: ">1y", MaxAge: 0},
+}
+
+// ageHistogram counts files and bytes per entry of ageBuckets.
+type ageHistogram struct {
+	Files []int64
+	Bytes []int64
+}
+
+// add records a file of the given age and size in the matching bucket.
+func (h *ageHistogram) add(age time.Duration, size int64) {
+	for i, bucket := range ageBuckets {
+		if bucket.MaxAge == 0 || age < bucket.MaxAge {
+			h.Files[i]++
+			h.Bytes[i] += size
+			return
+		}
+	}
+}
+
+// dirAgeReport holds the modification and access age histograms for one folder.
+type dirAgeReport struct {
+	Modified ageHistogram
+	Accessed ageHistogram
+}
+
+func newDirAgeReport() *dirAgeReport {
+	newHistogram := func() ageHistogram {
+		return ageHistogram{Files: make([]int64, len(ageBuckets)), Bytes: make([]int64, len(ageBuckets))}
+	}
+	return &dirAgeReport{Modified: newHistogram(), Accessed: newHistogram()}
+}
+
 // --- Global Variables ---
 // These variables are used across different parts of the application.
 var (
-	errorList      []string    // A slice to collect all non-fatal errors encountered during execution.
-	errorMutex     sync.Mutex  // A mutex to protect concurrent access to the errorList slice from multiple goroutines.
-	logger         *log.Logger // The global logger instance, configured based on --quiet and --log-file flags.
-	configFileUsed string      // Holds the path of the config file that was loaded, for display to the user.
+	errorList      []errorRecord      // A slice to collect all non-fatal errors encountered during execution.
+	errorMutex     sync.Mutex         // A mutex to protect concurrent access to the errorList slice from multiple goroutines.
+	logger         *slog.Logger       // The global logger instance, configured based on --quiet, --log-file, --log-format and --log-level.
+	configFileUsed string             // Holds the path of the config file that was loaded, for display to the user.
+	configProfiles map[string]Profile // All profiles defined in the loaded config file, as written.
+	activeProfile  Profile            // The --profile selected for this run, with inheritance resolved.
 )
 
 // --- Main Command Structure ---
@@ -101,19 +226,39 @@ var rootCmd = &cobra.Command{
 	Use:   "cleanup",
 	Short: "A powerful utility to find and manage files and folders.",
 	Long: `Cleanup is a command-line tool that helps you keep your filesystem tidy.
-It can find and delete empty folders, find duplicate files, and identify large directories.`,
+It can find and delete empty folders, find duplicate files, and identify large directories.
+
+Run 'cleanup --profile NAME' to execute a profile from the config file that defines its own command.
+
+Exit codes:
+  0    Success: nothing to do, or everything found was handled.
+  1    Fatal error (invalid flags or config, failed scan, aborted deletion).
+  2    Partial failure: completed, but some files could not be read or deleted.
+  3    Items were found but nothing was changed (dry run, decisions export, declined prompt).
+  130  Cancelled.`,
 	// PersistentPreRunE runs before any command's main execution function (RunE).
 	// It's used for setup tasks common to all subcommands.
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Record what is being run for the run summary; only 'empty' and 'find' delete anything.
+		recordCommand(cmd.Name(), args, isReadOnlyCommand(cmd.Name()))
 		// Initialize configuration from file, environment variables, and flags.
 		if err := initConfig(cmd); err != nil {
 			return err
 		}
 		// Set up the global logger based on the configuration.
 		if err := configureLogger(); err != nil {
-			// If the log file can't be opened, warn the user but don't crash.
-			fmt.Fprintf(os.Stderr, "⚠️  Could not open log file: %v. Logging to stderr only.\n", err)
+			return err
+		}
+		if err := checkOutputFlags(); err != nil {
+			return err
+		}
+		if err := checkFieldsFlag(cmd.Name()); err != nil {
+			return err
+		}
+		if err := checkFormatFlag(cmd.Name()); err != nil {
+			return err
 		}
+		openStream()
 		return nil
 	},
 	// PersistentPostRun runs after any command's main execution function.
@@ -122,6 +267,13 @@ It can find and delete empty folders, find duplicate files, and identify large d
 		// Print a summary of all non-fatal errors that occurred during the run.
 		printErrorSummary()
 	},
+	// RunE only does something when a profile is selected; a bare 'cleanup' shows the help.
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if config.Profile == "" {
+			return cmd.Help()
+		}
+		return runProfile(cmd, args)
+	},
 }
 
 // init is a special Go function that runs once when the package is initialized.
@@ -130,21 +282,34 @@ func init() {
 	// --- Persistent Flags ---
 	// These flags are available on the root command and all of its subcommands.
 	rootCmd.PersistentFlags().StringVar(&config.ConfigFile, "config", "", "Config file (default is ./.cleanup.yaml or $HOME/.cleanup.yaml)")
+	rootCmd.PersistentFlags().StringVar(&config.Profile, "profile", "", "Apply a named profile from the config file.")
 	rootCmd.PersistentFlags().BoolVarP(&config.Verbose, "verbose", "v", false, "Enable verbose output.")
 	rootCmd.PersistentFlags().BoolVarP(&config.Quiet, "quiet", "q", false, "Suppress all output except for errors.")
 	rootCmd.PersistentFlags().StringVarP(&config.LogFile, "log-file", "l", "", "Path to a file to write logs to.")
-	rootCmd.PersistentFlags().StringVarP(&config.OutputFormat, "output", "o", "", "Output results in a structured format: json|csv")
+	rootCmd.PersistentFlags().StringVar(&config.LogFormat, "log-format", "text", "Format of the log file (or of stderr, if no log file is set): text|json")
+	rootCmd.PersistentFlags().StringVar(&config.LogLevel, "log-level", "info", "Minimum level to log: debug|info|warn|error (--verbose implies debug).")
+	rootCmd.PersistentFlags().StringVarP(&config.OutputFormat, "output", "o", "", "Output results in a structured format: json|csv|tsv|markdown|html|ndjson")
+	rootCmd.PersistentFlags().StringVar(&config.OutputFile, "output-file", "", "Write structured results to a file instead of stdout (the format defaults to the file extension).")
+	rootCmd.PersistentFlags().BoolVar(&config.Print0, "print0", false, "Output only the paths of the results, each followed by a NUL character (for xargs -0).")
+	rootCmd.PersistentFlags().StringVar(&config.Format, "format", "", "Output each result with a Go template, e.g. '{{.Path}}\\t{{.Size | bytes}}\\t{{.ModTime | date}}' (Go field names, or the JSON names of 'cleanup schema').")
+	rootCmd.PersistentFlags().StringSliceVar(&config.Fields, "fields", []string{}, "Comma-separated list of columns for the csv, tsv, markdown and html formats, e.g. path,size (see 'cleanup schema').")
+	rootCmd.PersistentFlags().StringVar(&config.SummaryFile, "summary-file", "", "Write a JSON summary of the run (counts, bytes, errors, exit status) to a file.")
+	rootCmd.PersistentFlags().StringVar(&config.ErrorsOut, "errors-out", "", "Write all errors with path, operation and category to a file (.json, .csv, or tab-separated text).")
 	rootCmd.PersistentFlags().StringVarP(&config.ExcludePattern, "exclude-pattern", "p", "", "Exclude paths matching regex pattern.")
 	rootCmd.PersistentFlags().StringVarP(&config.ExcludeGlob, "exclude-glob", "g", "", "Exclude paths matching glob pattern on file/dir name.")
 	rootCmd.PersistentFlags().StringVar(&config.ExcludeGlobPath, "exclude-glob-path", "", "Exclude paths matching glob pattern on the full path.")
 	rootCmd.PersistentFlags().StringSliceVarP(&config.ExcludeDirs, "exclude-dirs", "x", []string{}, "Comma-separated list of directories to exclude by name.")
+	rootCmd.PersistentFlags().BoolVar(&config.IKnowWhatImDoing, "i-know-what-im-doing", false, "Allow cleaning up protected paths (system directories, $HOME, trees with a .cleanup-protect file).")
 
 	// --- Add Subcommands ---
 	// Each subcommand is initialized in its own function for better organization and clarity.
 	addEmptyCmd()
 	addFindCmd()
 	addLargeCmd()
+	addReportCmd()
+	addRunCmd()
 	addConfigCmd()
+	addSchemaCmd()
 	addVersionCmd()
 }
 
@@ -156,14 +321,14 @@ func main() {
 	defer stop() // Ensure the signal notification is cleaned up when main exits.
 
 	// Execute the root command with the cancellable context.
-	if err := rootCmd.ExecuteContext(ctx); err != nil {
-		// If the error is due to cancellation, print a user-friendly message.
-		if !errors.Is(err, context.Canceled) {
-			// For other errors, Cobra typically prints them, so we just exit with a non-zero code.
-			os.Exit(1)
-		}
-		fmt.Println("\n🚫 Operation cancelled by user.")
-	}
+	// Cobra prints any returned error itself; the exit code reflects the outcome of the run.
+	err := rootCmd.ExecuteContext(ctx)
+	code := finishRun(ctx, err)
+	if code == exitCancelled {
+		fmt.Fprintln(os.Stderr, "\n🚫 Operation cancelled by user.")
+	}
+	stop()
+	os.Exit(code)
 }
 
 // --- Command Definitions ---
@@ -171,12 +336,12 @@ func main() {
 // addEmptyCmd sets up the 'empty' subcommand and its specific flags.
 func addEmptyCmd() {
 	cmd := &cobra.Command{
-		Use:   "empty [PATH]",
+		Use:   "empty [PATH...]",
 		Short: "Find and delete empty folders",
-		Args:  cobra.MaximumNArgs(1), // Accepts zero or one argument (the path).
+		Args:  cobra.ArbitraryArgs, // Accepts any number of paths; defaults to the current directory.
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// The main execution logic for this command is in the runEmpty function.
-			return runEmpty(cmd.Context(), args)
+			return withProfilePaths(cmd.Context(), args, runEmpty)
 		},
 	}
 	// Flags specific to the 'empty' command.
@@ -184,17 +349,25 @@ func addEmptyCmd() {
 	cmd.Flags().BoolVarP(&config.DryRun, "dry-run", "d", false, "Perform a trial run without making any changes.")
 	cmd.Flags().BoolVarP(&config.Force, "force", "f", false, "Skip confirmation prompts.")
 	cmd.Flags().BoolVarP(&config.UseTrash, "trash", "t", false, "Move to system trash instead of deleting permanently.")
-	cmd.Flags().StringSliceVarP(&config.IgnoreFiles, "ignore-files", "i", []string{}, "Files to ignore when determining if a folder is empty.")
+	cmd.Flags().StringSliceVarP(&config.IgnoreFiles, "ignore-files", "i", []string{}, "Files to ignore when determining if a folder is empty: names or glob patterns (e.g., desktop.ini,*.tmp,._*).")
+	cmd.Flags().StringVar(&config.JunkPreset, "junk-preset", "", "Also ignore the junk files operating systems create: auto (this platform), all, macos, windows, linux.")
+	cmd.Flags().StringVar(&config.MaxJunkSize, "max-junk-size", "", "Only treat a folder as empty if its ignored files total at most this size (e.g., 1MB).")
+	cmd.Flags().BoolVar(&config.IncludeEmptyFiles, "include-empty-files", false, "Treat folders that contain only zero-byte files as empty.")
 	cmd.Flags().StringVarP(&config.OlderThanStr, "older-than", "O", "", "Only consider folders older than a duration (e.g., 30d, 4w, 12h, 90m).")
+	cmd.Flags().StringVar(&config.AgeBy, "age-by", "mtime", "Timestamp used for --older-than: mtime (modified), atime (accessed), ctime (changed), btime (created)")
+	addDeletionLimitFlags(cmd)
 	rootCmd.AddCommand(cmd)
 }
 
 // addFindCmd sets up the 'find' subcommand and its specific flags.
 func addFindCmd() {
 	cmd := &cobra.Command{
-		Use:   "find [PATH]",
+		Use:   "find [PATH...]",
 		Short: "Find files by criteria (duplicates, size, age)",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Finds files by criteria (duplicates, size, age, ownership) under one or more paths.
+With several paths, duplicates are detected across all of them and every result is
+attributed to the path it was found under.`,
+		Args: cobra.ArbitraryArgs,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			// This pre-run validation gives early feedback on invalid flag values.
 			if !contains([]string{"path", "size", "age"}, config.SortBy) {
@@ -203,10 +376,37 @@ func addFindCmd() {
 			if !contains([]string{"sha256", "sha1", "md5"}, config.HashAlgo) {
 				return fmt.Errorf("invalid value for --hash-algo: %q. Allowed values are: [sha256, sha1, md5]", config.HashAlgo)
 			}
+			if !contains([]string{"any", "all"}, config.MatchMode) {
+				return fmt.Errorf("invalid value for --match: %q. Allowed values are: [any, all]", config.MatchMode)
+			}
+			modes := 0
+			for _, enabled := range []bool{config.FindDuplicates, config.DuplicateDirs, config.SimilarImages} {
+				if enabled {
+					modes++
+				}
+			}
+			if modes > 1 {
+				return errors.New("only one of --find-duplicates, --duplicate-dirs and --similar-images can be used at a time")
+			}
+			if config.EmptyFiles && modes > 0 {
+				return errors.New("--empty-files cannot be combined with --find-duplicates, --duplicate-dirs or --similar-images")
+			}
+			if config.Decisions != "" && config.ExportDecisions != "" {
+				return errors.New("--decisions and --export-decisions cannot be used together")
+			}
+			if config.ExportDecisions != "" && modes == 0 {
+				return errors.New("--export-decisions requires --find-duplicates, --duplicate-dirs or --similar-images")
+			}
+			if !contains([]string{"dhash", "phash"}, config.ImageHash) {
+				return fmt.Errorf("invalid value for --image-hash: %q. Allowed values are: [dhash, phash]", config.ImageHash)
+			}
+			if config.SimilarityThreshold < 0 || config.SimilarityThreshold > 64 {
+				return fmt.Errorf("invalid value for --similarity-threshold: %d. It must be between 0 and 64", config.SimilarityThreshold)
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runFind(cmd.Context(), args)
+			return withProfilePaths(cmd.Context(), args, runFind)
 		},
 	}
 	// Flags specific to the 'find' command.
@@ -214,28 +414,123 @@ func addFindCmd() {
 	cmd.Flags().BoolVarP(&config.Force, "force", "f", false, "Skip confirmation prompts for deletion.")
 	cmd.Flags().BoolVarP(&config.UseTrash, "trash", "t", false, "Move files to system trash instead of deleting.")
 	cmd.Flags().StringVarP(&config.OlderThanStr, "older-than", "O", "", "Find files older than a duration (e.g., 30d, 4w, 12h, 90m).")
+	cmd.Flags().StringVar(&config.AgeBy, "age-by", "mtime", "Timestamp used for --older-than: mtime (modified), atime (accessed), ctime (changed), btime (created)")
+	cmd.Flags().StringVar(&config.NewerThanStr, "newer-than", "", "Find files newer than a duration (e.g., 30d, 4w, 12h, 90m).")
+	cmd.Flags().StringVar(&config.ModifiedBefore, "modified-before", "", "Find files last modified before a date (e.g., 2025-01-01 or 2025-01-01T12:00:00).")
+	cmd.Flags().StringVar(&config.ModifiedAfter, "modified-after", "", "Find files last modified after a date (e.g., 2025-01-01 or 2025-01-01T12:00:00).")
 	cmd.Flags().StringVarP(&config.FilesOverStr, "files-over", "S", "", "Find files larger than a size (e.g., 100MB, 2GB).")
+	cmd.Flags().StringVar(&config.FilesUnderStr, "files-under", "", "Find files smaller than a size (e.g., 4KB, 1MB).")
+	cmd.Flags().StringVar(&config.SizeRange, "size", "", "Find files within a size range, either bound optional (e.g., 10MB..1GB, ..4KB).")
+	cmd.Flags().StringVar(&config.MatchMode, "match", "any", "How to combine size/age/date filters: any (at least one matches) | all (every filter matches). --where, ownership and permission filters always have to match.")
+	cmd.Flags().StringVarP(&config.Where, "where", "w", "", `Filter expression, e.g. 'size > 100MB && (ext == "log" || name ~ "^core\.") && age > 30d'.
+Fields: name, ext, path, owner, group (==, !=, ~, !~; owner == 1000 compares the UID); size, age, modified, uid, gid (==, !=, <, <=, >, >=).
+Combine with &&, || and !, group with parentheses.`)
+	cmd.Flags().StringVar(&config.Owner, "owner", "", "Find files owned by a user (name or numeric UID).")
+	cmd.Flags().StringVar(&config.Group, "group", "", "Find files owned by a group (name or numeric GID).")
+	cmd.Flags().StringVar(&config.UID, "uid", "", "Find files owned by a numeric UID.")
+	cmd.Flags().BoolVar(&config.NoUser, "nouser", false, "Find files whose UID does not belong to any known user (orphaned files).")
+	cmd.Flags().StringVar(&config.Perm, "perm", "", "Find files by octal permission bits: 644 (exactly), -644 (all of these bits), /022 (any of these bits).")
+	cmd.Flags().BoolVar(&config.WorldWritable, "world-writable", false, "Find files that anyone can write to.")
+	cmd.Flags().BoolVar(&config.EmptyFiles, "empty-files", false, "Find zero-byte files. Can be combined with the other filters.")
+	cmd.Flags().StringSliceVarP(&config.IgnoreFiles, "ignore-files", "i", []string{}, "With --empty-files, names or glob patterns of files that are expected to be empty and are never reported (e.g., .gitkeep,__init__.py).")
 	cmd.Flags().BoolVarP(&config.FindDuplicates, "find-duplicates", "D", false, "Find duplicate files by content hash.")
-	cmd.Flags().StringVar(&config.DuplicateKeep, "keep", "prompt", "Duplicate handling strategy: prompt, newest, oldest, first (alphabetical).")
+	cmd.Flags().BoolVar(&config.DuplicateDirs, "duplicate-dirs", false, "Find identical directory trees (same names and file contents) and remove redundant copies.")
+	cmd.Flags().BoolVar(&config.SimilarImages, "similar-images", false, "Find visually similar JPEG/PNG/GIF images (resized or re-encoded copies) by perceptual hash.")
+	cmd.Flags().StringVar(&config.ImageHash, "image-hash", "dhash", "Perceptual hash for --similar-images: dhash (fast) | phash (more tolerant of re-encoding)")
+	cmd.Flags().IntVar(&config.SimilarityThreshold, "similarity-threshold", 6, "Maximum number of differing hash bits (0-64) for two images to count as similar.")
+	cmd.Flags().StringVar(&config.DuplicateKeep, "keep", "prompt", `Duplicate handling strategy: prompt, or a comma-separated chain where later entries break ties:
+newest, oldest, first (alphabetical), shortest-path, deepest, shallowest, most-links, largest-resolution,
+prefer-path=GLOB, avoid-path=GLOB, prefer-root=PATH (e.g. prefer-path=/photos/*,oldest).`)
+	cmd.Flags().StringVar(&config.ExportDecisions, "export-decisions", "", "Write the duplicate sets with the keeper chosen by --keep to a file (.json for JSON, else text) instead of deleting.")
+	cmd.Flags().StringVar(&config.Decisions, "decisions", "", "Apply the keep:/delete: choices of an edited --export-decisions file instead of scanning.")
 	cmd.Flags().StringVar(&config.SortBy, "sort", "path", "Sort results by: path|size|age")
 	cmd.Flags().StringVar(&config.HashAlgo, "hash-algo", "sha256", "Hash algorithm for finding duplicates: sha256|sha1|md5")
+	addDeletionLimitFlags(cmd)
 	rootCmd.AddCommand(cmd)
 }
 
 // addLargeCmd sets up the 'large' subcommand.
 func addLargeCmd() {
 	cmd := &cobra.Command{
-		Use:   "large [PATH]",
-		Short: "Find the largest folders in a directory",
-		Args:  cobra.MaximumNArgs(1),
+		Use:   "large [PATH...]",
+		Short: "Find the largest folders in one or more directories",
+		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runLarge(cmd.Context(), args)
+			return withProfilePaths(cmd.Context(), args, runLarge)
 		},
 	}
 	cmd.Flags().IntVarP(&config.TopN, "top", "n", 10, "Number of largest folders to show.")
 	rootCmd.AddCommand(cmd)
 }
 
+// addReportCmd sets up the 'report' subcommand.
+func addReportCmd() {
+	cmd := &cobra.Command{
+		Use:   "report [PATH...]",
+		Short: "Show an age histogram of file data per top-level folder",
+		Long: `Buckets the bytes and file counts under each top-level folder by how long ago
+the files were last modified and last accessed (0-7d, 7-30d, 30-90d, 90d-1y, >1y).
+Use it to pick sensible --older-than values before deleting anything.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withProfilePaths(cmd.Context(), args, runReport)
+		},
+	}
+	rootCmd.AddCommand(cmd)
+}
+
+// addRunCmd sets up the 'run' subcommand for executing a jobs file.
+func addRunCmd() {
+	var parallel int
+	cmd := &cobra.Command{
+		Use:   "run JOBS_FILE",
+		Short: "Run a list of cleanup jobs from a YAML file",
+		Long: `Runs the jobs defined in a YAML file, in order or in parallel, and prints a combined summary.
+Each job names a command (empty, find, large, report), its target paths, flags, and an action
+(dry-run, delete, trash). Jobs never prompt; the default action is dry-run.
+
+Example jobs file:
+
+  parallel: 2
+  jobs:
+    - name: ci-cache
+      command: find
+      paths: [/var/cache/ci]
+      flags: {older-than: 30d}
+      action: trash
+    - name: empty-dirs
+      command: empty
+      paths: [/srv/data]
+      flags: {recursive: true}
+      action: delete
+
+The command exits with a non-zero status if any job fails or reports errors.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true, // A failed job is not a usage error.
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobFile, err := loadJobFile(args[0])
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("parallel") {
+				jobFile.Parallel = parallel
+			}
+			logInfo("--- 📋 Job Runner Mode ---")
+			logInfo("📄 Jobs File: %s (%d job(s))", args[0], len(jobFile.Jobs))
+			if jobFile.Parallel > 1 {
+				logInfo("⚡ Parallel: up to %d jobs at once", jobFile.Parallel)
+			} else {
+				logInfo("➡️  Parallel: Disabled (jobs run in order)")
+			}
+			printCommonSummary(true)
+			logInfo("----------------------------------\n")
+			return runJobs(cmd.Context(), jobFile)
+		},
+	}
+	cmd.Flags().IntVarP(&parallel, "parallel", "P", 1, "Maximum number of jobs to run at once (overrides the jobs file).")
+	rootCmd.AddCommand(cmd)
+}
+
 // addConfigCmd sets up the 'config' subcommand for managing the configuration file.
 func addConfigCmd() {
 	var isGlobal bool
@@ -271,26 +566,61 @@ Use the --global flag to create it in your home directory instead.`,
 			// Define a complete default configuration struct.
 			// This ensures all user-configurable fields are written to the file.
 			defaultConfig := Config{
-				Recursive:       false,
-				DryRun:          false,
-				Force:           false,
-				Verbose:         false,
-				Quiet:           false,
-				UseTrash:        false,
-				OutputFormat:    "",
-				LogFile:         "",
-				IgnoreFiles:     []string{".DS_Store", "Thumbs.db"},
-				ExcludeDirs:     []string{".git", "node_modules", "vendor", "tmp"},
-				ExcludePattern:  "",
-				ExcludeGlob:     "",
-				ExcludeGlobPath: "",
-				OlderThanStr:    "",
-				FilesOverStr:    "",
-				TopN:            10,
-				DuplicateKeep:   "prompt",
-				FindDuplicates:  false,
-				HashAlgo:        "sha256",
-				SortBy:          "path",
+				Recursive:           false,
+				DryRun:              false,
+				Force:               false,
+				Verbose:             false,
+				Quiet:               false,
+				UseTrash:            false,
+				OutputFormat:        "",
+				OutputFile:          "",
+				Print0:              false,
+				Fields:              []string{},
+				Format:              "",
+				LogFile:             "",
+				LogFormat:           "text",
+				LogLevel:            "info",
+				ErrorsOut:           "",
+				SummaryFile:         "",
+				IgnoreFiles:         []string{".DS_Store", "Thumbs.db"},
+				IncludeEmptyFiles:   false,
+				JunkPreset:          "",
+				MaxJunkSize:         "",
+				EmptyFiles:          false,
+				ExcludeDirs:         []string{".git", "node_modules", "vendor", "tmp"},
+				ExcludePattern:      "",
+				ExcludeGlob:         "",
+				ExcludeGlobPath:     "",
+				OlderThanStr:        "",
+				AgeBy:               "mtime",
+				NewerThanStr:        "",
+				ModifiedBefore:      "",
+				ModifiedAfter:       "",
+				FilesOverStr:        "",
+				FilesUnderStr:       "",
+				SizeRange:           "",
+				MatchMode:           "any",
+				Where:               "",
+				Owner:               "",
+				Group:               "",
+				UID:                 "",
+				NoUser:              false,
+				Perm:                "",
+				WorldWritable:       false,
+				TopN:                10,
+				DuplicateKeep:       "prompt",
+				FindDuplicates:      false,
+				DuplicateDirs:       false,
+				SimilarImages:       false,
+				ImageHash:           "dhash",
+				SimilarityThreshold: 6,
+				HashAlgo:            "sha256",
+				SortBy:              "path",
+				ProtectedPaths:      []string{},
+				MaxDeleteCount:      0,
+				MaxDeleteSize:       "",
+				MaxDeletePercent:    0,
+				MaxErrorRate:        50,
 			}
 
 			// Marshal the struct into YAML format.
@@ -308,9 +638,94 @@ Use the --global flag to create it in your home directory instead.`,
 	}
 	initCmd.Flags().BoolVar(&isGlobal, "global", false, "Create the config file in the user's home directory")
 	configCmd.AddCommand(initCmd)
+
+	listProfilesCmd := &cobra.Command{
+		Use: "list-profiles", Short: "List the profiles defined in the config file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if configFileUsed == "" {
+				return errors.New("no config file found; create one with 'cleanup config init'")
+			}
+			if len(configProfiles) == 0 {
+				fmt.Printf("No profiles defined in %s\n", configFileUsed)
+				return nil
+			}
+			var names []string
+			for name := range configProfiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Printf("Profiles in %s:\n", configFileUsed)
+			for _, name := range names {
+				line := fmt.Sprintf("  • %s", name)
+				resolved, err := resolveProfile(name)
+				if err != nil {
+					fmt.Printf("%s: ⚠️  %v\n", line, err)
+					continue
+				}
+				if inherits := configProfiles[name].Inherits; inherits != "" {
+					line += fmt.Sprintf(" (inherits %s)", inherits)
+				}
+				if resolved.Command != "" {
+					line += fmt.Sprintf(": %s", resolved.Command)
+				}
+				if len(resolved.Paths) > 0 {
+					line += fmt.Sprintf(" %s", strings.Join(resolved.Paths, " "))
+				}
+				var keys []string
+				for key, value := range resolved.Settings {
+					keys = append(keys, fmt.Sprintf("%s=%v", key, value))
+				}
+				sort.Strings(keys)
+				if len(keys) > 0 {
+					line += fmt.Sprintf(" [%s]", strings.Join(keys, ", "))
+				}
+				fmt.Println(line)
+			}
+			return nil
+		},
+	}
+	configCmd.AddCommand(listProfilesCmd)
 	rootCmd.AddCommand(configCmd)
 }
 
+// addSchemaCmd sets up the 'schema' subcommand.
+func addSchemaCmd() {
+	cmd := &cobra.Command{
+		Use:   "schema [NAME]",
+		Short: "Print the JSON Schema of a command's output",
+		Long: fmt.Sprintf(`Print the JSON Schema of the JSON output of a command, or of all outputs if no name is given.
+
+Names: %s.
+The schemas are versioned; the current version (%d) is also reported as 'schema_version' in the run summary.`,
+			strings.Join(schemaNames(), ", "), resultSchemaVersion),
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: schemaNames(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var document interface{}
+			if len(args) == 1 {
+				schema := findSchema(args[0])
+				if schema == nil {
+					return fmt.Errorf("invalid schema name: %q. Allowed values are: [%s]", args[0], strings.Join(schemaNames(), ", "))
+				}
+				document = schema.jsonSchema()
+			} else {
+				all := make(map[string]interface{})
+				for _, schema := range resultSchemas {
+					all[schema.name] = schema.jsonSchema()
+				}
+				document = map[string]interface{}{"version": resultSchemaVersion, "schemas": all}
+			}
+			data, err := json.MarshalIndent(document, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		},
+	}
+	rootCmd.AddCommand(cmd)
+}
+
 // addVersionCmd sets up the 'version' subcommand.
 func addVersionCmd() {
 	versionCmd := &cobra.Command{
@@ -328,6 +743,59 @@ func addVersionCmd() {
 
 // --- Command Execution Logic ---
 
+// runProfile runs the subcommand named by the active profile, as if it had been typed on the command line.
+func runProfile(root *cobra.Command, args []string) error {
+	if activeProfile.Command == "" {
+		return fmt.Errorf("profile %q does not define a command; run it as 'cleanup <command> --profile %s'", config.Profile, config.Profile)
+	}
+	var sub *cobra.Command
+	for _, c := range root.Commands() {
+		if c.Name() == activeProfile.Command && contains([]string{"empty", "find", "large", "report"}, c.Name()) {
+			sub = c
+		}
+	}
+	if sub == nil {
+		return fmt.Errorf("profile %q has an invalid command %q. Allowed values are: [empty, find, large, report]", config.Profile, activeProfile.Command)
+	}
+
+	// The summary describes the subcommand that actually runs, not the bare 'cleanup'.
+	recordCommand(sub.Name(), args, isReadOnlyCommand(sub.Name()))
+
+	// Re-resolve the configuration so the subcommand's own flag defaults are applied.
+	if err := initConfig(sub); err != nil {
+		return err
+	}
+	if err := checkOutputFlags(); err != nil {
+		return err
+	}
+	if err := checkFieldsFlag(sub.Name()); err != nil {
+		return err
+	}
+	if err := checkFormatFlag(sub.Name()); err != nil {
+		return err
+	}
+	sub.SetContext(root.Context())
+	if sub.PreRunE != nil {
+		if err := sub.PreRunE(sub, args); err != nil {
+			return err
+		}
+	}
+	return sub.RunE(sub, args)
+}
+
+// withProfilePaths calls run with the given arguments, or with the paths of the active
+// profile if no path was given on the command line.
+func withProfilePaths(ctx context.Context, args []string, run func(context.Context, []string) error) error {
+	if len(args) > 0 || len(activeProfile.Paths) == 0 {
+		return run(ctx, args)
+	}
+	var paths []string
+	for _, path := range activeProfile.Paths {
+		paths = append(paths, expandHome(path))
+	}
+	return run(ctx, paths)
+}
+
 // runEmpty contains the core logic for the 'empty' command.
 func runEmpty(ctx context.Context, args []string) error {
 	runCtx, err := newRunContext()
@@ -335,61 +803,92 @@ func runEmpty(ctx context.Context, args []string) error {
 		return err
 	}
 
-	targetDir, err := getTargetDir(args)
+	targetDirs, err := getTargetDirs(args)
 	if err != nil {
 		return err
 	}
-	printEmptyModeSummary(targetDir)
-
-	var allEmptyDirs []string
-	if config.Recursive {
-		allEmptyDirs, err = findEmptyRecursive(ctx, targetDir, runCtx)
-	} else {
-		allEmptyDirs, err = findEmptyTopLevel(ctx, targetDir, runCtx)
-	}
-	if err != nil {
-		addError(fmt.Errorf("error during scan: %w", err))
+	if err := getPathGuard().checkTargets(targetDirs); err != nil {
 		return err
 	}
-	if ctx.Err() != nil {
-		return ctx.Err()
+	printEmptyModeSummary(targetDirs)
+	warnIfAtimeUnreliable(targetDirs, runCtx)
+
+	var allEmptyDirs []string
+	for _, targetDir := range targetDirs {
+		var emptyDirs []string
+		if config.Recursive {
+			emptyDirs, err = findEmptyRecursive(ctx, targetDir, runCtx)
+		} else {
+			emptyDirs, err = findEmptyTopLevel(ctx, targetDir, runCtx)
+		}
+		if err != nil {
+			addError(fmt.Errorf("error during scan: %w", err))
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		allEmptyDirs = append(allEmptyDirs, emptyDirs...)
 	}
 
 	if len(allEmptyDirs) > 0 {
 		logInfo("\n🔎 Found %d empty folder(s):", len(allEmptyDirs))
-		var outputData []map[string]interface{}
+		var results []emptyDirResult
 		for _, dir := range allEmptyDirs {
-			outputData = append(outputData, map[string]interface{}{"path": dir})
+			results = append(results, newEmptyDirResult(dir, targetDirs))
 		}
-		outputResults(outputData, []string{"path"})
+		recordResults(len(results))
+		outputResults(results)
 
 		// Filter the list to get only the top-most parents for safe deletion.
 		finalDirsToDelete := filterSubdirectories(allEmptyDirs)
 		logInfo("\n🚮 Preparing to delete %d top-level empty folder(s)...", len(finalDirsToDelete))
-		handleDeletion("empty folders", finalDirsToDelete, 0)
+		return handleDeletion("empty folders", finalDirsToDelete, nil)
 	} else {
 		logInfo("\n🎉 Success! No empty folders were found.")
+		outputResults([]emptyDirResult{})
 	}
 	return nil
 }
 
 // runFind contains the core logic for the 'find' command.
 func runFind(ctx context.Context, args []string) error {
+	if config.Decisions != "" {
+		if len(args) > 0 {
+			return errors.New("--decisions takes the paths from the decisions file; do not pass any paths")
+		}
+		return applyDecisions(config.Decisions)
+	}
 	runCtx, err := newRunContext()
 	if err != nil {
 		return err
 	}
 
-	targetDir, err := getTargetDir(args)
+	targetDirs, err := getTargetDirs(args)
 	if err != nil {
 		return err
 	}
-	printFindModeSummary(targetDir)
+	if err := getPathGuard().checkTargets(targetDirs); err != nil {
+		return err
+	}
+	printFindModeSummary(targetDirs)
+	warnIfAtimeUnreliable(targetDirs, runCtx)
 
-	if config.FindDuplicates {
-		return findDuplicates(ctx, targetDir, runCtx)
+	if config.FindDuplicates || config.DuplicateDirs || config.SimilarImages {
+		// Parse the keep strategy before scanning so that a typo doesn't waste a long hashing run.
+		strategy, err := parseKeepStrategy(config.DuplicateKeep, targetDirs)
+		if err != nil {
+			return err
+		}
+		if config.DuplicateDirs {
+			return findDuplicateDirs(ctx, targetDirs, runCtx, strategy)
+		}
+		if config.SimilarImages {
+			return findSimilarImages(ctx, targetDirs, runCtx, strategy)
+		}
+		return findDuplicates(ctx, targetDirs, runCtx, strategy)
 	}
-	return findFilesByCriteria(ctx, targetDir, runCtx)
+	return findFilesByCriteria(ctx, targetDirs, runCtx)
 }
 
 // runLarge contains the core logic for the 'large' command.
@@ -399,28 +898,25 @@ func runLarge(ctx context.Context, args []string) error {
 		return err
 	}
 
-	targetDir, err := getTargetDir(args)
+	targetDirs, err := getTargetDirs(args)
 	if err != nil {
 		return err
 	}
-	printLargeModeSummary(targetDir)
+	printLargeModeSummary(targetDirs)
 	logInfo("⏳ Scanning... this may take a while. Press Ctrl+C to cancel.")
 
-	dirSizes, err := calculateDirectorySizes(ctx, targetDir, runCtx)
-	if err != nil {
-		return err
-	}
-	if ctx.Err() != nil {
-		return ctx.Err()
-	}
-
-	type dirInfo struct {
-		Path string `json:"path"`
-		Size int64  `json:"size"`
-	}
-	var sortedDirs []dirInfo
-	for path, size := range dirSizes {
-		sortedDirs = append(sortedDirs, dirInfo{path, size})
+	var sortedDirs []largeDirResult
+	for _, targetDir := range targetDirs {
+		dirSizes, err := calculateDirectorySizes(ctx, targetDir, runCtx)
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		for path, size := range dirSizes {
+			sortedDirs = append(sortedDirs, largeDirResult{Path: path, Root: targetDir, Size: size, SizeFormatted: formatBytes(size)})
+		}
 	}
 
 	sort.Slice(sortedDirs, func(i, j int) bool { return sortedDirs[i].Size > sortedDirs[j].Size })
@@ -430,20 +926,86 @@ func runLarge(ctx context.Context, args []string) error {
 		limit = len(sortedDirs)
 	}
 	results := sortedDirs[:limit]
+	for i := range results {
+		results[i].ModTime = modTimeOf(results[i].Path)
+	}
 	logInfo("\n🔎 Top %d largest folders:", limit)
 
-	var outputData []map[string]interface{}
-	for _, dir := range results {
-		outputData = append(outputData, map[string]interface{}{"path": dir.Path, "size": dir.Size, "size_formatted": formatBytes(dir.Size)})
+	recordResults(len(results))
+	outputResults(results)
+	return nil
+}
+
+// runReport contains the core logic for the 'report' command.
+func runReport(ctx context.Context, args []string) error {
+	runCtx, err := newRunContext()
+	if err != nil {
+		return err
+	}
+
+	targetDirs, err := getTargetDirs(args)
+	if err != nil {
+		return err
+	}
+	printReportModeSummary(targetDirs)
+	for _, targetDir := range targetDirs {
+		if isNoatimeMount(targetDir) {
+			logInfo("⚠️  %s is on a filesystem mounted with 'noatime'; the Last Accessed columns are unreliable.", targetDir)
+		}
+	}
+	logInfo("⏳ Scanning... this may take a while. Press Ctrl+C to cancel.")
+
+	reports := make(map[string]*dirAgeReport)
+	for _, targetDir := range targetDirs {
+		rootReports, err := buildAgeReport(ctx, targetDir, runCtx)
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Roots never overlap (see getTargetDirs), so their top-level folders are distinct.
+		for dir, report := range rootReports {
+			reports[dir] = report
+		}
+	}
+
+	var dirs []string
+	for dir := range reports {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	logInfo("\n📅 Age distribution for %d top-level folder(s):", len(dirs))
+
+	if config.OutputFormat == "" {
+		printAgeReport(dirs, reports)
+		return nil
+	}
+
+	var results []reportResult
+	for _, dir := range dirs {
+		report := reports[dir]
+		for i, bucket := range ageBuckets {
+			results = append(results, reportResult{
+				Path:          dir,
+				Root:          rootOf(targetDirs, dir),
+				Bucket:        bucket.Label,
+				ModifiedFiles: report.Modified.Files[i],
+				ModifiedBytes: report.Modified.Bytes[i],
+				AccessedFiles: report.Accessed.Files[i],
+				AccessedBytes: report.Accessed.Bytes[i],
+			})
+		}
 	}
-	outputResults(outputData, []string{"path", "size", "size_formatted"})
+	recordResults(len(results))
+	outputResults(results)
 	return nil
 }
 
 // --- Core Logic ---
 
-// findFilesByCriteria scans for files based on size and age filters.
-func findFilesByCriteria(ctx context.Context, targetDir string, runCtx *runContext) error {
+// findFilesByCriteria scans for files based on size, age and date filters.
+func findFilesByCriteria(ctx context.Context, targetDirs []string, runCtx *runContext) error {
 	var foundFiles []fileResult
 	var mu sync.Mutex
 
@@ -451,40 +1013,87 @@ func findFilesByCriteria(ctx context.Context, targetDir string, runCtx *runConte
 		if runCtx.shouldExclude(path) || !info.Mode().IsRegular() {
 			return
 		}
+		if config.EmptyFiles {
+			// Empty-file mode always requires a zero-byte file, whatever --match says about the other filters.
+			if info.Size() != 0 {
+				return
+			}
+			if runCtx.ignore.matches(filepath.Base(path)) {
+				logVerbose("Keeping allowed empty file: %s", path)
+				return
+			}
+		}
 
-		matchSize := runCtx.filesOverBytes > 0 && info.Size() >= runCtx.filesOverBytes
-		matchAge := !runCtx.olderThan.IsZero() && info.ModTime().Before(runCtx.olderThan)
-		noCriteria := runCtx.filesOverBytes == 0 && runCtx.olderThan.IsZero()
-
-		if matchSize || matchAge || noCriteria {
+		if runCtx.matchesCriteria(path, info) {
+			file := fileResult{Path: path, Info: info}
+			// Streamed results go out as soon as they are found, not sorted at the end.
+			emitEvent(eventFound, newFileMatchResult(file, targetDirs))
 			mu.Lock()
-			foundFiles = append(foundFiles, fileResult{Path: path, Info: info})
+			foundFiles = append(foundFiles, file)
 			mu.Unlock()
 		}
 	}
 
-	if err := scanFilesParallel(ctx, targetDir, processFile); err != nil {
+	if err := scanFilesParallel(ctx, targetDirs, getPathGuard().skipDir, processFile); err != nil {
 		return err
 	}
 	sortResults(foundFiles)
 	logInfo("\n🔎 Found %d files matching criteria.", len(foundFiles))
 
 	var pathsToDelete []string
-	var totalSize int64
-	var outputData []map[string]interface{}
+	sizes := make(map[string]int64, len(foundFiles))
+	var results []fileMatchResult
 	for _, file := range foundFiles {
 		pathsToDelete = append(pathsToDelete, file.Path)
-		totalSize += file.Info.Size()
-		outputData = append(outputData, map[string]interface{}{"path": file.Path, "size": file.Info.Size(), "modified": file.Info.ModTime().Format(time.RFC3339)})
+		sizes[file.Path] = file.Info.Size()
+		if !streaming() {
+			results = append(results, newFileMatchResult(file, targetDirs))
+		}
 	}
 
-	outputResults(outputData, []string{"path", "size", "modified"})
-	handleDeletion("matching files", pathsToDelete, totalSize)
-	return nil
+	recordResults(len(foundFiles))
+	if !streaming() {
+		outputResults(results)
+	}
+	itemType := "matching files"
+	if config.EmptyFiles {
+		itemType = "empty files"
+	}
+	return handleDeletion(itemType, pathsToDelete, sizes)
+}
+
+// newFileMatchResult returns the result record of a found file.
+func newFileMatchResult(file fileResult, targetDirs []string) fileMatchResult {
+	owner, group := ownerColumns(file.Info)
+	return fileMatchResult{
+		Path:    file.Path,
+		Root:    rootOf(targetDirs, file.Path),
+		Size:    file.Info.Size(),
+		ModTime: file.Info.ModTime().Truncate(time.Second), // Whole seconds, as in RFC 3339.
+		Owner:   owner,
+		Group:   group,
+		Mode:    fmt.Sprintf("%04o", file.Info.Mode().Perm()),
+	}
+}
+
+// newEmptyDirResult builds the result record of an empty folder. Its size is that of the
+// ignored files it still contains.
+func newEmptyDirResult(dir string, targetDirs []string) emptyDirResult {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return emptyDirResult{Path: dir, Root: rootOf(targetDirs, dir), Size: size, ModTime: modTimeOf(dir)}
 }
 
 // findDuplicates scans for files with identical content hashes.
-func findDuplicates(ctx context.Context, targetDir string, runCtx *runContext) error {
+// With several target directories, duplicates are matched across all of them.
+func findDuplicates(ctx context.Context, targetDirs []string, runCtx *runContext, strategy *keepStrategy) error {
 	hashes := make(map[string][]string)
 	var mu sync.Mutex
 
@@ -503,38 +1112,167 @@ func findDuplicates(ctx context.Context, targetDir string, runCtx *runContext) e
 		mu.Unlock()
 	}
 
-	if err := scanFilesParallel(ctx, targetDir, processFile); err != nil {
+	if err := scanFilesParallel(ctx, targetDirs, getPathGuard().skipDir, processFile); err != nil {
 		return err
 	}
 
-	var duplicatesToProcess [][]string
+	var groups []*duplicateGroup
 	for hash, files := range hashes {
 		if len(files) > 1 {
-			logVerbose("Found duplicate set for hash %s: %v", hash, files)
-			duplicatesToProcess = append(duplicatesToProcess, files)
+			logVerbose("Found duplicate set for hash %s: %v", hash, describeRoots(targetDirs, files))
+			sort.Strings(files)
+			groups = append(groups, &duplicateGroup{Hash: hash, Size: getFileSize(files[0]), Paths: files})
 		}
 	}
+	sortDuplicateGroups(groups)
 
-	logInfo("\n👯 Found %d sets of duplicate files.", len(duplicatesToProcess))
-	if len(duplicatesToProcess) == 0 {
-		return nil
-	}
-
-	var pathsToDelete []string
-	var totalSizeDeleted int64
-	for i, set := range duplicatesToProcess {
-		toDelete, err := processDuplicateSet(set, i+1)
+	logInfo("\n👯 Found %d sets of duplicate files.", len(groups))
+	for i, group := range groups {
+		keep, toDelete, err := processDuplicateSet(group.Paths, i+1, group.Size, strategy)
 		if err != nil {
 			addError(err)
 			continue
 		}
-		pathsToDelete = append(pathsToDelete, toDelete...)
-		for _, p := range toDelete {
-			totalSizeDeleted += getFileSize(p)
+		group.decide(keep, toDelete, getFileSize)
+	}
+	return finishDuplicateGroups("files", "duplicate files", groups, targetDirs, getFileSize)
+}
+
+// duplicateGroup is one set of duplicates together with the decision made for it.
+type duplicateGroup struct {
+	Hash        string           // Content hash, directory tree hash or perceptual hash of the set.
+	Size        int64            // Size of one copy in bytes.
+	Paths       []string         // All copies, sorted.
+	Keep        string           // The copy that is kept; empty if the set was skipped.
+	Delete      []string         // The copies that are removed.
+	Reclaimable int64            // Bytes freed by removing them.
+	sizes       map[string]int64 // Size of each copy in Delete.
+}
+
+// decide records the outcome of the keep strategy for the group: the copy it kept, which is
+// empty if the set was skipped, and the copies it removes.
+func (g *duplicateGroup) decide(keep string, toDelete []string, sizeOf func(string) int64) {
+	g.Keep, g.Delete = keep, toDelete
+	g.Reclaimable, g.sizes = 0, make(map[string]int64, len(toDelete))
+	for _, p := range toDelete {
+		g.sizes[p] = sizeOf(p)
+		g.Reclaimable += g.sizes[p]
+	}
+}
+
+// sortDuplicateGroups orders groups by the space they waste, largest first, so that set numbers
+// are stable between runs and the most rewarding sets come first.
+func sortDuplicateGroups(groups []*duplicateGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		wi := groups[i].Size * int64(len(groups[i].Paths)-1)
+		wj := groups[j].Size * int64(len(groups[j].Paths)-1)
+		if wi != wj {
+			return wi > wj
+		}
+		return groups[i].Paths[0] < groups[j].Paths[0]
+	})
+}
+
+// outputDuplicateGroups reports the duplicate sets and the space that removing them reclaims.
+// JSON gets one object per group plus the totals, the table formats and --format one row per copy
+// with its group's columns.
+func outputDuplicateGroups(groups []*duplicateGroup, targetDirs []string) {
+	recordResults(len(groups))
+	report := duplicateReport{Groups: []duplicateSetResult{}, TotalGroups: len(groups)}
+	var copies []duplicateCopyResult
+	var deletePaths []string
+	for i, g := range groups {
+		report.TotalReclaimable += g.Reclaimable
+		report.Groups = append(report.Groups, duplicateSetResult{
+			Group:                i + 1,
+			Hash:                 g.Hash,
+			Size:                 g.Size,
+			Count:                len(g.Paths),
+			Paths:                g.Paths,
+			Keep:                 g.Keep,
+			Reclaimable:          g.Reclaimable,
+			ReclaimableFormatted: formatBytes(g.Reclaimable),
+		})
+		for _, p := range g.Paths {
+			action := "skip"
+			if g.Keep == p {
+				action = "keep"
+			} else if contains(g.Delete, p) {
+				action = "delete"
+			}
+			copies = append(copies, duplicateCopyResult{
+				Group: i + 1, Hash: g.Hash, Size: g.Size, Count: len(g.Paths),
+				Path: p, Root: rootOf(targetDirs, p), ModTime: modTimeOf(p), Action: action, Reclaimable: g.Reclaimable,
+			})
+		}
+		deletePaths = append(deletePaths, g.Delete...)
+	}
+	report.TotalReclaimableFormatted = formatBytes(report.TotalReclaimable)
+
+	switch config.OutputFormat {
+	case "json":
+		if writer := resultWriter(); writer != nil {
+			outputJSON(report, writer)
+		}
+	case "csv", "tsv", "markdown", "html", "template":
+		outputResults(copies)
+	case "ndjson":
+		outputResults(report.Groups)
+	case "print0":
+		// Only the copies that would be removed, so the list can be handed to another tool.
+		if writer := resultWriter(); writer != nil {
+			writePrint0(deletePaths, writer)
+		}
+	default:
+		for i, g := range groups {
+			if g.Keep == "" {
+				logInfo("  • Set %d (%d copies of %s): skipped", i+1, len(g.Paths), formatBytes(g.Size))
+				continue
+			}
+			logInfo("  • Set %d (%d copies of %s): keep %s, reclaim %s", i+1, len(g.Paths), formatBytes(g.Size), g.Keep, formatBytes(g.Reclaimable))
 		}
 	}
-	handleDeletion("duplicate files", pathsToDelete, totalSizeDeleted)
-	return nil
+	if len(groups) > 0 {
+		logInfo("♻️  Reclaimable space: %s in %d set(s).", report.TotalReclaimableFormatted, len(groups))
+	}
+}
+
+// buildAgeReport walks the filesystem and buckets every regular file by age under its top-level folder.
+// Files directly inside targetDir are attributed to targetDir itself.
+func buildAgeReport(ctx context.Context, targetDir string, runCtx *runContext) (map[string]*dirAgeReport, error) {
+	reports := make(map[string]*dirAgeReport)
+	now := time.Now()
+	var mu sync.Mutex
+
+	processFile := func(path string, info os.FileInfo) {
+		if runCtx.shouldExclude(path) || !info.Mode().IsRegular() {
+			return
+		}
+		topDir := targetDir
+		if rel, err := filepath.Rel(targetDir, path); err == nil {
+			if parts := strings.SplitN(rel, string(os.PathSeparator), 2); len(parts) == 2 {
+				topDir = filepath.Join(targetDir, parts[0])
+			}
+		}
+		times := statTimes(info)
+
+		mu.Lock()
+		defer mu.Unlock()
+		report, ok := reports[topDir]
+		if !ok {
+			report = newDirAgeReport()
+			reports[topDir] = report
+		}
+		report.Modified.add(now.Sub(times.Modified), info.Size())
+		if !times.Accessed.IsZero() {
+			report.Accessed.add(now.Sub(times.Accessed), info.Size())
+		}
+	}
+	err := scanFilesParallel(ctx, []string{targetDir}, nil, processFile)
+	if err != nil {
+		addError(fmt.Errorf("age report scan failed: %w", err))
+	}
+	return reports, err
 }
 
 // calculateDirectorySizes walks the filesystem and sums up file sizes.
@@ -553,7 +1291,7 @@ func calculateDirectorySizes(ctx context.Context, targetDir string, runCtx *runC
 			mu.Unlock()
 		}
 	}
-	err := scanFilesParallel(ctx, targetDir, processFile)
+	err := scanFilesParallel(ctx, []string{targetDir}, nil, processFile)
 	if err != nil {
 		addError(fmt.Errorf("directory size calculation failed: %w", err))
 	}
@@ -563,8 +1301,7 @@ func calculateDirectorySizes(ctx context.Context, targetDir string, runCtx *runC
 // findEmptyRecursive finds all empty folders in a directory tree.
 func findEmptyRecursive(ctx context.Context, path string, runCtx *runContext) ([]string, error) {
 	var allDirs []string
-	deletablePaths := make(map[string]bool)
-	ignoreFileSet := stringSliceToSet(config.IgnoreFiles)
+	deletablePaths := make(map[string]int64) // Empty directories and the size of the ignored files in them.
 
 	logVerbose("Phase 1: Walking filesystem to collect all directories...")
 	walkErr := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
@@ -584,8 +1321,12 @@ func findEmptyRecursive(ctx context.Context, path string, runCtx *runContext) ([
 			}
 			return nil
 		}
+		if d.IsDir() && p != path && getPathGuard().skipDir(p) {
+			return filepath.SkipDir
+		}
 		if d.IsDir() {
 			allDirs = append(allDirs, p)
+			countScanned(0)
 		}
 		return nil
 	})
@@ -602,14 +1343,14 @@ func findEmptyRecursive(ctx context.Context, path string, runCtx *runContext) ([
 		if dir == path {
 			continue
 		}
-		isDirEmpty, err := isDirectoryEmpty(dir, ignoreFileSet, deletablePaths, runCtx)
+		isDirEmpty, junkSize, err := isDirectoryEmpty(dir, deletablePaths, runCtx)
 		if err != nil {
 			addError(err)
 			continue
 		}
 		if isDirEmpty {
 			logVerbose("    ✅ Marked as empty: %s", dir)
-			deletablePaths[dir] = true
+			deletablePaths[dir] = junkSize
 		}
 	}
 
@@ -624,7 +1365,6 @@ func findEmptyRecursive(ctx context.Context, path string, runCtx *runContext) ([
 // findEmptyTopLevel finds empty folders only in the top level of the given path.
 func findEmptyTopLevel(ctx context.Context, path string, runCtx *runContext) ([]string, error) {
 	var emptyDirs []string
-	ignoreFileSet := stringSliceToSet(config.IgnoreFiles)
 
 	entries, err := os.ReadDir(path)
 	if err != nil {
@@ -643,11 +1383,12 @@ func findEmptyTopLevel(ctx context.Context, path string, runCtx *runContext) ([]
 
 		fullPath := filepath.Join(path, entry.Name())
 		logVerbose("-> Evaluating top-level directory: %s", fullPath)
-		if runCtx.shouldExclude(fullPath) {
+		countScanned(0)
+		if runCtx.shouldExclude(fullPath) || getPathGuard().skipDir(fullPath) {
 			continue
 		}
 
-		isDirEmpty, err := isDirectoryEmpty(fullPath, ignoreFileSet, nil, runCtx)
+		isDirEmpty, _, err := isDirectoryEmpty(fullPath, nil, runCtx)
 		if err != nil {
 			addError(fmt.Errorf("could not evaluate dir %s: %w", fullPath, err))
 			continue
@@ -662,16 +1403,26 @@ func findEmptyTopLevel(ctx context.Context, path string, runCtx *runContext) ([]
 }
 
 // --- Parallel Scanner ---
-func scanFilesParallel(ctx context.Context, targetDir string, processFunc func(string, os.FileInfo)) error {
+// If skipDir is not nil, directories below the target directories for which it returns true are left out.
+func scanFilesParallel(ctx context.Context, targetDirs []string, skipDir func(string) bool, processFunc func(string, os.FileInfo)) error {
+	isSkipped := func(path, targetDir string, d os.DirEntry) bool {
+		return skipDir != nil && d.IsDir() && path != targetDir && skipDir(path)
+	}
+
 	var fileCount int64
 	if !config.Quiet {
 		logVerbose("Pre-scanning to count files for progress bar...")
-		_ = filepath.WalkDir(targetDir, func(path string, d os.DirEntry, err error) error {
-			if err == nil && !d.IsDir() {
-				fileCount++
-			}
-			return nil
-		})
+		for _, targetDir := range targetDirs {
+			_ = filepath.WalkDir(targetDir, func(path string, d os.DirEntry, err error) error {
+				if err == nil && isSkipped(path, targetDir, d) {
+					return filepath.SkipDir
+				}
+				if err == nil && !d.IsDir() {
+					fileCount++
+				}
+				return nil
+			})
+		}
 	}
 
 	bar := progressbar.NewOptions64(fileCount,
@@ -696,23 +1447,28 @@ func scanFilesParallel(ctx context.Context, targetDir string, processFunc func(s
 	// Walker Goroutine
 	go func() {
 		defer close(paths)
-		_ = filepath.WalkDir(targetDir, func(path string, d os.DirEntry, err error) error {
-			if ctx.Err() != nil {
-				return ctx.Err()
-			}
-			if err != nil {
-				addError(fmt.Errorf("access error on %s: %w", path, err))
-				return nil
-			}
-			if !d.IsDir() {
-				select {
-				case paths <- path:
-				case <-ctx.Done():
+		for _, targetDir := range targetDirs {
+			_ = filepath.WalkDir(targetDir, func(path string, d os.DirEntry, err error) error {
+				if ctx.Err() != nil {
 					return ctx.Err()
 				}
-			}
-			return nil
-		})
+				if err != nil {
+					addError(fmt.Errorf("access error on %s: %w", path, err))
+					return nil
+				}
+				if isSkipped(path, targetDir, d) {
+					return filepath.SkipDir
+				}
+				if !d.IsDir() {
+					select {
+					case paths <- path:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+				return nil
+			})
+		}
 	}()
 
 	// Worker Goroutines
@@ -731,6 +1487,7 @@ func scanFilesParallel(ctx context.Context, targetDir string, processFunc func(s
 					if err != nil {
 						addError(err)
 					} else {
+						countScanned(info.Size())
 						processFunc(path, info)
 					}
 					_ = bar.Add(1)
@@ -746,10 +1503,36 @@ func scanFilesParallel(ctx context.Context, targetDir string, processFunc func(s
 
 // --- Helper & Utility Functions ---
 
-// handleDeletion manages the user confirmation and deletion process.
-func handleDeletion(itemType string, paths []string, totalSize int64) {
+// handleDeletion manages the user confirmation and deletion process. sizes holds the size of
+// each path where it is known and may be nil, e.g. for empty folders.
+// It returns an error if a deletion limit is exceeded or the error-rate circuit breaker trips.
+func handleDeletion(itemType string, paths []string, sizes map[string]int64) error {
+	// Check every path once more, whichever scan produced it.
+	guard := getPathGuard()
+	var allowed []string
+	for _, p := range paths {
+		if err := guard.checkDeletion(p); err != nil {
+			addError(err)
+			continue
+		}
+		allowed = append(allowed, p)
+	}
+	paths = allowed
 	if len(paths) == 0 {
-		return
+		return nil
+	}
+	var totalSize int64
+	for _, p := range paths {
+		totalSize += sizes[p]
+	}
+	recordCandidates(len(paths), totalSize)
+	if err := checkDeletionLimits(len(paths), totalSize); err != nil {
+		// A dry run still lists the candidates, so the limit can be checked against them.
+		var limit *limitError
+		if !config.DryRun || !errors.As(err, &limit) {
+			return err
+		}
+		logInfo("⚠️  Without --dry-run, this run would abort: %s.", limit.reason)
 	}
 
 	if config.DryRun {
@@ -759,11 +1542,12 @@ func handleDeletion(itemType string, paths []string, totalSize int64) {
 			logInfo("Total size that would be freed: %s", formatBytes(totalSize))
 		}
 		for _, p := range paths {
-			logInfo("  - %s", p)
+			logEvent(slog.LevelInfo, "  - "+p, "path", p, "action", "dry-run", "bytes", sizes[p])
 		}
 		logInfo("--------------------------")
 		logInfo("No changes were made.")
-		return
+		markItemsFound()
+		return nil
 	}
 
 	if !config.Force {
@@ -772,12 +1556,13 @@ func handleDeletion(itemType string, paths []string, totalSize int64) {
 		if !config.UseTrash {
 			logInfo("\033[31mThis action is PERMANENT and CANNOT be undone.\033[0m")
 		}
-		fmt.Printf("\033[34m❓ Are you sure you want to proceed? [yes/No] \033[0m")
+		fmt.Fprintf(os.Stderr, "\033[34m❓ Are you sure you want to proceed? [yes/No] \033[0m")
 		reader := bufio.NewReader(os.Stdin)
 		response, _ := reader.ReadString('\n')
 		if strings.ToLower(strings.TrimSpace(response)) != "yes" && strings.ToLower(strings.TrimSpace(response)) != "y" {
 			logInfo("\n👍 OK. No changes were made.")
-			return
+			markItemsFound()
+			return nil
 		}
 	}
 
@@ -785,10 +1570,13 @@ func handleDeletion(itemType string, paths []string, totalSize int64) {
 	processedCount := 0
 	bar := progressbar.NewOptions(len(paths),
 		progressbar.OptionSetDescription(fmt.Sprintf("%s items...", getActionStringPresent())),
+		progressbar.OptionSetWriter(os.Stderr), // Keep stdout free for structured output.
 		progressbar.OptionSetVisibility(!config.Quiet && !config.Verbose),
 	)
 
-	for _, path := range paths {
+	breaker := newErrorBreaker(config.MaxErrorRate)
+	var freedBytes int64
+	for i, path := range paths {
 		var opErr error
 		if config.UseTrash {
 			opErr = trash.Throw(path)
@@ -796,14 +1584,24 @@ func handleDeletion(itemType string, paths []string, totalSize int64) {
 			opErr = os.RemoveAll(path)
 		}
 		if opErr != nil {
-			addError(fmt.Errorf("error %s %s: %w", getActionStringPast(), path, opErr))
+			addError(tagError(getActionName(), path, fmt.Errorf("error %s %s: %w", getActionStringPast(), path, opErr)))
 		} else {
-			logVerbose("  %s %s: %s", getActionIcon(), getActionStringPast(), path)
+			logEvent(slog.LevelDebug, fmt.Sprintf("  %s %s: %s", getActionIcon(), getActionStringPast(), path),
+				"path", path, "action", getActionName(), "bytes", sizes[path])
 			processedCount++
+			freedBytes += sizes[path]
+			emitEvent(eventDeleted, deletedEvent{Path: path, Action: getActionName(), Bytes: sizes[path]})
 		}
+		recordDeletion(opErr != nil, sizes[path])
 		_ = bar.Add(1)
+		if breaker.record(opErr != nil) {
+			return fmt.Errorf("stopped after %d of %d %s: %.0f%% of the recent deletions failed, more than --max-error-rate %g. %s %d %s before stopping",
+				i+1, len(paths), itemType, breaker.rate(), config.MaxErrorRate, getActionStringPast(), processedCount, itemType)
+		}
 	}
-	logInfo("\n✨ All done! %s %d %s.", getActionStringPast(), processedCount, itemType)
+	logEvent(slog.LevelInfo, fmt.Sprintf("\n✨ All done! %s %d %s.", getActionStringPast(), processedCount, itemType),
+		"action", getActionName(), "count", processedCount, "bytes", freedBytes)
+	return nil
 }
 
 // initConfig reads configuration from file, env vars, and flags, establishing a clear precedence.
@@ -847,27 +1645,153 @@ func initConfig(cmd *cobra.Command) error {
 		}
 	}
 
+	// Apply the selected profile on top of the top-level config values.
+	// Its settings are merged into the config layer, so flags and env vars still take precedence.
+	configProfiles = nil
+	if err := v.UnmarshalKey("profiles", &configProfiles); err != nil {
+		return fmt.Errorf("invalid 'profiles' section in config file: %w", err)
+	}
+	// The profile comes from the --profile flag only: AutomaticEnv would also let an unrelated
+	// PROFILE environment variable select one, without config.Profile knowing about it.
+	activeProfile = Profile{}
+	if profileName := config.Profile; profileName != "" {
+		profile, err := resolveProfile(profileName)
+		if err != nil {
+			return err
+		}
+		if err := v.MergeConfigMap(profile.Settings); err != nil {
+			return err
+		}
+		activeProfile = profile
+	}
+
 	// Finally, unmarshal all the resolved values (from flags, env, or config) into our struct.
 	return v.Unmarshal(&config)
 }
 
-// getTargetDir resolves the input argument to an absolute, validated directory path.
-func getTargetDir(args []string) (string, error) {
-	pathArg := "."
-	if len(args) > 0 {
-		pathArg = args[0]
+// resolveProfile returns the named profile with its 'inherits' chain applied.
+// Settings from the profile itself win over those it inherits.
+func resolveProfile(name string) (Profile, error) {
+	resolved := Profile{Settings: make(map[string]interface{})}
+	var chain []string
+	for current := name; current != ""; current = configProfiles[current].Inherits {
+		if _, ok := configProfiles[current]; !ok {
+			if current == name {
+				return Profile{}, fmt.Errorf("unknown profile %q. Run 'cleanup config list-profiles' to see the available profiles", name)
+			}
+			return Profile{}, fmt.Errorf("profile %q inherits from unknown profile %q", chain[len(chain)-1], current)
+		}
+		if contains(chain, current) {
+			return Profile{}, fmt.Errorf("profile %q has an inheritance cycle: %s -> %s", name, strings.Join(chain, " -> "), current)
+		}
+		chain = append(chain, current)
+	}
+
+	// Walk from the base-most profile to the selected one so that later profiles override earlier ones.
+	for i := len(chain) - 1; i >= 0; i-- {
+		profile := configProfiles[chain[i]]
+		if profile.Command != "" {
+			resolved.Command = profile.Command
+		}
+		if len(profile.Paths) > 0 {
+			resolved.Paths = profile.Paths
+		}
+		for key, value := range profile.Settings {
+			resolved.Settings[key] = value
+		}
 	}
+	resolved.Inherits = configProfiles[name].Inherits
+	return resolved, nil
+}
+
+// getTargetDir resolves a path argument to an absolute, validated directory path.
+func getTargetDir(pathArg string) (string, error) {
 	targetDir, err := filepath.Abs(pathArg)
 	if err != nil {
 		return "", fmt.Errorf("could not resolve path '%s': %w", pathArg, err)
 	}
 	info, err := os.Stat(targetDir)
-	if os.IsNotExist(err) || !info.IsDir() {
+	if err != nil || !info.IsDir() {
 		return "", fmt.Errorf("the specified path does not exist or is not a directory: %s", targetDir)
 	}
 	return targetDir, nil
 }
 
+// getTargetDirs resolves all path arguments (default: the current directory) to validated directories.
+// Paths that lie inside another given path are dropped, so no file is ever scanned twice;
+// otherwise a file could be reported as a duplicate of itself.
+func getTargetDirs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	var resolved []string
+	for _, arg := range args {
+		targetDir, err := getTargetDir(arg)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, targetDir)
+	}
+
+	var targetDirs []string
+	for _, dir := range resolved {
+		if outer := rootOf(resolved, dir); outer != dir {
+			logInfo("⚠️  Skipping %s because it is inside %s, which is also being scanned.", dir, outer)
+			continue
+		}
+		if !contains(targetDirs, dir) {
+			targetDirs = append(targetDirs, dir)
+		}
+	}
+	recordTargets(targetDirs)
+	return targetDirs, nil
+}
+
+// rootOf returns the outermost of the given root directories that contains path,
+// or "" if none of them does.
+func rootOf(roots []string, path string) string {
+	best := ""
+	for _, root := range roots {
+		if isWithin(root, path) && (best == "" || len(root) < len(best)) {
+			best = root
+		}
+	}
+	return best
+}
+
+// isWithin reports whether path is dir itself or lies somewhere below it.
+func isWithin(dir, path string) bool {
+	if path == dir {
+		return true
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// describeRoots formats paths with the root each was found under, for verbose logging.
+func describeRoots(roots []string, paths []string) []string {
+	if len(roots) < 2 {
+		return paths
+	}
+	described := make([]string, len(paths))
+	for i, p := range paths {
+		described[i] = fmt.Sprintf("%s [%s]", p, rootOf(roots, p))
+	}
+	return described
+}
+
+// expandHome replaces a leading '~' in a path from the config file with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(os.PathSeparator)) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
 // parseDuration converts a human-readable duration string (e.g., "30d", "4w") into a time.Duration.
 func parseDuration(s string) (time.Duration, error) {
 	s = strings.ToLower(strings.TrimSpace(s))
@@ -935,6 +1859,76 @@ func parseSize(s string) (int64, error) {
 	return int64(num * multiplier), nil
 }
 
+// parseSizeRange converts a range such as "10MB..1GB" into inclusive byte bounds.
+// Either side may be omitted; a missing upper bound is returned as -1.
+func parseSizeRange(s string) (int64, int64, error) {
+	lower, upper, found := strings.Cut(strings.TrimSpace(s), "..")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid size range: %q. Use formats like '10MB..1GB', '10MB..' or '..1GB'", s)
+	}
+	if strings.TrimSpace(lower) == "" && strings.TrimSpace(upper) == "" {
+		return 0, 0, errors.New("size range needs at least one bound")
+	}
+
+	var minBytes, maxBytes int64 = 0, -1
+	var err error
+	if strings.TrimSpace(lower) != "" {
+		if minBytes, err = parseSize(lower); err != nil {
+			return 0, 0, err
+		}
+	}
+	if strings.TrimSpace(upper) != "" {
+		if maxBytes, err = parseSize(upper); err != nil {
+			return 0, 0, err
+		}
+		if maxBytes < minBytes {
+			return 0, 0, fmt.Errorf("lower bound %q is larger than upper bound %q", lower, upper)
+		}
+	}
+	return minBytes, maxBytes, nil
+}
+
+// parseDate converts an absolute date (e.g., "2025-01-01" or "2025-01-01T15:04:05") into a time.Time.
+// Dates without a zone are interpreted in local time.
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, errors.New("date string cannot be empty")
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date format: %q. Use formats like '2025-01-01' or '2025-01-01T15:04:05'", s)
+}
+
+// parsePerm converts a find(1)-style permission argument into a test function.
+// "644" matches exactly, "-644" requires all of the bits and "/022" requires any of them.
+func parsePerm(s string) (func(os.FileMode) bool, error) {
+	s = strings.TrimSpace(s)
+	mode := s
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "/") {
+		mode = s[1:]
+	}
+	bits, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || bits > 0o777 {
+		return nil, fmt.Errorf("invalid permission mode: %q. Use octal formats like '644', '-600' or '/022'", s)
+	}
+	want := os.FileMode(bits)
+	switch s[0] {
+	case '-':
+		return func(perm os.FileMode) bool { return perm&want == want }, nil
+	case '/':
+		return func(perm os.FileMode) bool { return perm&want != 0 }, nil
+	default:
+		return func(perm os.FileMode) bool { return perm == want }, nil
+	}
+}
+
 // formatBytes converts a byte count into a human-readable string.
 func formatBytes(b int64) string {
 	const unit = 1024
@@ -951,16 +1945,7 @@ func formatBytes(b int64) string {
 
 // hashFile computes the hash of a file's content using the configured algorithm.
 func hashFile(path string) (string, error) {
-	var h hash.Hash
-	switch config.HashAlgo {
-	case "md5":
-		h = md5.New()
-	case "sha1":
-		h = sha1.New()
-	default:
-		h = sha256.New()
-	}
-
+	h := newHasher()
 	file, err := os.Open(path)
 	if err != nil {
 		return "", fmt.Errorf("could not open file %s for hashing: %w", path, err)
@@ -973,67 +1958,93 @@ func hashFile(path string) (string, error) {
 	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
+// newHasher returns a new hash of the algorithm selected with --hash-algo.
+func newHasher() hash.Hash {
+	switch config.HashAlgo {
+	case "md5":
+		return md5.New()
+	case "sha1":
+		return sha1.New()
+	}
+	return sha256.New()
+}
+
 // addError safely adds an error to the global error list and logs it.
 func addError(err error) {
 	if err == nil {
 		return
 	}
+	record := newErrorRecord(err)
 	errorMutex.Lock()
 	defer errorMutex.Unlock()
-	errorList = append(errorList, err.Error())
-	logError(err.Error())
+	errorList = append(errorList, record)
+	emitEvent(eventError, record)
+	if logger == nil {
+		logError("%s", record.Message)
+		return
+	}
+	logEvent(slog.LevelError, record.Message, record.logFields()...)
 }
 
 // printErrorSummary prints all collected errors at the end of execution.
 func printErrorSummary() {
-	errorMutex.Lock()
-	defer errorMutex.Unlock()
-	if len(errorList) > 1 {
-		logInfo("\n--- ⚠️ Encountered %d Error(s) Summary ---", len(errorList))
-		for i, e := range errorList {
-			logInfo("%d: %s", i+1, e)
-		}
-		logInfo("---------------------------------------")
+	records := collectedErrors()
+	if len(records) == 0 {
+		return
+	}
+	logInfo("\n--- ⚠️ Encountered %d Error(s) Summary ---", len(records))
+	for i, e := range records {
+		logInfo("%d: [%s] %s", i+1, e.Category, e.Message)
 	}
+	logInfo("---------------------------------------")
 }
 
-// outputResults handles writing results to console or a file in the specified format.
-func outputResults(data interface{}, headers []string) {
+// outputResults writes result records, a slice of one of the result types, in the output format.
+func outputResults(records interface{}) {
+	rows := reflect.ValueOf(records)
+	if streaming() {
+		for i := 0; i < rows.Len(); i++ {
+			emitEvent(eventFound, rows.Index(i).Interface())
+		}
+		return
+	}
 	if config.OutputFormat == "" {
-		switch v := data.(type) {
-		case []map[string]interface{}:
-			for _, row := range v {
-				if path, ok := row["path"]; ok {
-					line := fmt.Sprintf("  • %s", path)
-					if size, ok := row["size_formatted"]; ok {
-						line += fmt.Sprintf(" (%s)", size)
-					}
-					logInfo("%s", line)
-				}
+		for i := 0; i < rows.Len(); i++ {
+			row := rows.Index(i)
+			path, ok := recordField(row, "path")
+			if !ok {
+				continue
+			}
+			line := fmt.Sprintf("  • %s", path)
+			if size, ok := recordField(row, "size_formatted"); ok {
+				line += fmt.Sprintf(" (%s)", size)
+			}
+			fields := []any{"path", path, "action", "found"}
+			if size, ok := recordField(row, "size"); ok {
+				fields = append(fields, "bytes", size)
 			}
+			logEvent(slog.LevelInfo, line, fields...)
 		}
 		return
 	}
 
-	var writer io.Writer = os.Stdout
-	if config.Quiet {
-		if config.LogFile == "" {
-			return
-		}
-		file, err := os.OpenFile(config.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-		if err != nil {
-			addError(fmt.Errorf("failed to open log file for results: %w", err))
-			return
-		}
-		defer file.Close()
-		writer = file
+	writer := resultWriter()
+	if writer == nil {
+		return
 	}
-
 	switch config.OutputFormat {
 	case "json":
-		outputJSON(data, writer)
-	case "csv":
-		outputCSV(data, headers, writer)
+		if rows.Len() == 0 {
+			// An empty list rather than null, as the schema says.
+			records = reflect.MakeSlice(rows.Type(), 0, 0).Interface()
+		}
+		outputJSON(records, writer)
+	case "print0":
+		outputPrint0(rows, writer)
+	case "template":
+		outputTemplate(rows, writer)
+	default:
+		outputTable(rows, writer)
 	}
 }
 
@@ -1046,166 +2057,230 @@ func outputJSON(data interface{}, writer io.Writer) {
 	fmt.Fprintln(writer, string(jsonData))
 }
 
-func outputCSV(data interface{}, headers []string, writer io.Writer) {
-	csvWriter := csv.NewWriter(writer)
-	defer csvWriter.Flush()
-
-	if err := csvWriter.Write(headers); err != nil {
-		addError(fmt.Errorf("failed to write CSV header: %w", err))
-		return
-	}
-	switch v := data.(type) {
-	case []map[string]interface{}:
-		for _, rowMap := range v {
-			var record []string
-			for _, h := range headers {
-				if val, ok := rowMap[h]; ok {
-					record = append(record, fmt.Sprintf("%v", val))
-				} else {
-					record = append(record, "")
-				}
-			}
-			if err := csvWriter.Write(record); err != nil {
-				addError(fmt.Errorf("failed to write CSV record: %w", err))
-			}
-		}
-	default:
-		addError(fmt.Errorf("unsupported data type for CSV output: %T", data))
-	}
-}
-
-// configureLogger sets up the global logger based on config flags.
-func configureLogger() error {
-	var writers []io.Writer
-	if !config.Quiet {
-		writers = append(writers, os.Stderr)
-	}
-	if config.LogFile != "" {
-		file, err := os.OpenFile(config.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-		if err != nil {
-			return err
-		}
-		writers = append(writers, file)
-	}
-
-	out := io.Discard
-	if len(writers) > 0 {
-		out = io.MultiWriter(writers...)
-	}
-	logger = log.New(out, "", 0)
-	return nil
-}
-
-// --- Logging Wrappers ---
-func logInfo(format string, v ...interface{}) {
-	if logger != nil {
-		logger.Printf(format, v...)
-	}
-}
-func logVerbose(format string, v ...interface{}) {
-	if config.Verbose && logger != nil {
-		logger.Printf("[VERBOSE] "+format, v...)
-	}
-}
-func logError(format string, v ...interface{}) {
-	fmt.Fprintf(os.Stderr, "\033[31m[ERROR] "+format+"\033[0m\n", v...)
-}
-
 // isDirectoryEmpty checks if a directory contains no files and no non-deletable subdirectories.
-func isDirectoryEmpty(dir string, ignoreFileSet map[string]struct{}, deletableSubDirs map[string]bool, runCtx *runContext) (bool, error) {
+// Ignored files don't count, as long as together with those in deletable subdirectories they
+// stay within --max-junk-size. It also returns that total size.
+func isDirectoryEmpty(dir string, deletableSubDirs map[string]int64, runCtx *runContext) (bool, int64, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
 	if !runCtx.olderThan.IsZero() {
 		info, err := os.Stat(dir)
 		if err != nil {
-			return false, err
+			return false, 0, err
+		}
+		t := runCtx.fileTime(dir, info)
+		if t.IsZero() {
+			logVerbose("    - Dir '%s' has no %s, skipping check.", dir, runCtx.ageBy)
+			return false, 0, nil
 		}
-		if info.ModTime().After(runCtx.olderThan) {
+		if t.After(runCtx.olderThan) {
 			logVerbose("    - Dir '%s' is too new, skipping check.", dir)
-			return false, nil
+			return false, 0, nil
 		}
 	}
+	var junkSize int64
 	for _, entry := range entries {
 		if !entry.IsDir() {
-			if _, ignored := ignoreFileSet[entry.Name()]; !ignored {
+			if config.IncludeEmptyFiles && entry.Type().IsRegular() {
+				if info, err := entry.Info(); err == nil && info.Size() == 0 {
+					logVerbose("    - Contains zero-byte file: %s. Treating it as empty.", entry.Name())
+					continue
+				}
+			}
+			if !runCtx.ignore.matches(entry.Name()) {
 				logVerbose("    - Contains non-ignored file: %s. Marking as NOT empty.", entry.Name())
-				return false, nil
+				return false, 0, nil
+			}
+			if info, err := entry.Info(); err == nil {
+				junkSize += info.Size()
 			}
 		} else {
 			if deletableSubDirs == nil {
-				return false, nil
+				return false, 0, nil
 			}
 			subDirPath := filepath.Join(dir, entry.Name())
-			if !deletableSubDirs[subDirPath] {
+			subJunkSize, deletable := deletableSubDirs[subDirPath]
+			if !deletable {
 				logVerbose("    - Contains non-empty subdirectory: %s. Marking as NOT empty.", subDirPath)
-				return false, nil
+				return false, 0, nil
 			}
+			junkSize += subJunkSize
 		}
 	}
-	return true, nil
+	if runCtx.maxJunkSize >= 0 && junkSize > runCtx.maxJunkSize {
+		logVerbose("    - Ignored files total %s, more than --max-junk-size. Marking as NOT empty.", formatBytes(junkSize))
+		return false, 0, nil
+	}
+	return true, junkSize, nil
 }
 
-// processDuplicateSet applies the chosen strategy to a set of duplicate files.
-func processDuplicateSet(set []string, setIndex int) ([]string, error) {
-	if len(set) < 2 {
-		return nil, nil
+// duplicateFile describes one member of a duplicate set for the keep strategies.
+type duplicateFile struct {
+	Path    string
+	ModTime time.Time
+	Links   uint64
+	Pixels  int64 // Image resolution; only read if the strategy uses largest-resolution.
+}
+
+// keepRule is one entry of a --keep chain. compare returns a negative number if a should be
+// kept in preference to b, a positive number for the opposite, and 0 if the rule cannot
+// tell them apart, in which case the next rule in the chain decides.
+type keepRule struct {
+	name    string
+	compare func(a, b duplicateFile) int
+}
+
+// keepStrategy is a parsed --keep value: either the interactive prompt or a chain of rules.
+type keepStrategy struct {
+	prompt     bool
+	rules      []keepRule
+	resolution bool // Whether a rule needs duplicateFile.Pixels.
+}
+
+// parseKeepStrategy parses a --keep value such as "newest" or "prefer-path=/photos/*,oldest".
+// The roots are the target directories of the run, which prefer-root must refer to.
+func parseKeepStrategy(spec string, roots []string) (*keepStrategy, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.EqualFold(spec, "prompt") {
+		if config.Force || config.ExportDecisions != "" || !stdinIsTerminal() {
+			// There is nobody to ask with --force or without a terminal, e.g. in a job or cron,
+			// and an exported file is edited afterwards, so fall back to the alphabetical order.
+			spec = "first"
+		} else {
+			return &keepStrategy{prompt: true}, nil
+		}
 	}
-	type fileInfo struct {
-		Path    string
-		ModTime time.Time
+
+	strategy := &keepStrategy{}
+	for _, part := range strings.Split(spec, ",") {
+		name, arg, hasArg := strings.Cut(strings.TrimSpace(part), "=")
+		name = strings.ToLower(name)
+		if hasArg != contains([]string{"prefer-path", "avoid-path", "prefer-root"}, name) {
+			if hasArg {
+				return nil, fmt.Errorf("invalid --keep entry %q: %s does not take a value", part, name)
+			}
+			return nil, fmt.Errorf("invalid --keep entry %q: %s needs a value, e.g. %s=/photos", part, name, name)
+		}
+
+		rule := keepRule{name: part}
+		switch name {
+		case "newest":
+			rule.compare = func(a, b duplicateFile) int { return b.ModTime.Compare(a.ModTime) }
+		case "oldest":
+			rule.compare = func(a, b duplicateFile) int { return a.ModTime.Compare(b.ModTime) }
+		case "first":
+			rule.compare = func(a, b duplicateFile) int { return strings.Compare(a.Path, b.Path) }
+		case "shortest-path":
+			rule.compare = func(a, b duplicateFile) int { return len(a.Path) - len(b.Path) }
+		case "deepest":
+			rule.compare = func(a, b duplicateFile) int { return pathDepth(b.Path) - pathDepth(a.Path) }
+		case "shallowest":
+			rule.compare = func(a, b duplicateFile) int { return pathDepth(a.Path) - pathDepth(b.Path) }
+		case "most-links":
+			rule.compare = func(a, b duplicateFile) int { return compareBool(a.Links > b.Links, b.Links > a.Links) }
+		case "largest-resolution":
+			rule.compare = func(a, b duplicateFile) int { return compareBool(a.Pixels > b.Pixels, b.Pixels > a.Pixels) }
+			strategy.resolution = true
+		case "prefer-path", "avoid-path":
+			pattern := expandHome(arg)
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob pattern in --keep %s: %w", part, err)
+			}
+			prefer := name == "prefer-path"
+			rule.compare = func(a, b duplicateFile) int {
+				matchA, matchB := pathMatchesGlob(pattern, a.Path), pathMatchesGlob(pattern, b.Path)
+				if !prefer {
+					matchA, matchB = !matchA, !matchB
+				}
+				return compareBool(matchA, matchB)
+			}
+		case "prefer-root":
+			preferred, err := filepath.Abs(expandHome(arg))
+			if err != nil || !contains(roots, preferred) {
+				return nil, fmt.Errorf("--keep prefer-root=%s must name one of the target paths: %s", arg, strings.Join(roots, ", "))
+			}
+			rule.compare = func(a, b duplicateFile) int {
+				return compareBool(rootOf(roots, a.Path) == preferred, rootOf(roots, b.Path) == preferred)
+			}
+		case "prompt":
+			return nil, errors.New("--keep prompt cannot be combined with other strategies")
+		default:
+			return nil, fmt.Errorf("unknown duplicate keep strategy: '%s'", part)
+		}
+		strategy.rules = append(strategy.rules, rule)
 	}
-	var files []fileInfo
+	return strategy, nil
+}
+
+// stdinIsTerminal reports whether the standard input is an interactive terminal that prompts can
+// be answered on. It is a variable so tests can pretend to be interactive.
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// processDuplicateSet applies the chosen strategy to a set of duplicate files or directories and
+// returns the copy it keeps and those to remove; both are empty if the set is skipped.
+// setSize is the size of a single copy, shown when prompting.
+func processDuplicateSet(set []string, setIndex int, setSize int64, strategy *keepStrategy) (string, []string, error) {
+	if len(set) < 2 {
+		return "", nil, nil
+	}
+	var files []duplicateFile
 	for _, p := range set {
 		info, err := os.Stat(p)
 		if err != nil {
-			return nil, fmt.Errorf("could not stat file %s: %w", p, err)
+			return "", nil, fmt.Errorf("could not stat file %s: %w", p, err)
 		}
-		files = append(files, fileInfo{Path: p, ModTime: info.ModTime()})
+		file := duplicateFile{Path: p, ModTime: info.ModTime(), Links: hardLinkCount(info)}
+		if strategy.resolution {
+			file.Pixels = imagePixels(p)
+		}
+		files = append(files, file)
 	}
 
 	var fileToKeep string
 	var filesToDelete []string
 
-	strategy := config.DuplicateKeep
-	if config.Force && strategy == "prompt" {
-		strategy = "first"
-	}
-
-	switch strings.ToLower(strategy) {
-	case "newest":
-		sort.Slice(files, func(i, j int) bool { return files[i].ModTime.After(files[j].ModTime) })
-		fileToKeep = files[0].Path
-	case "oldest":
-		sort.Slice(files, func(i, j int) bool { return files[i].ModTime.Before(files[j].ModTime) })
-		fileToKeep = files[0].Path
-	case "first":
-		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
-		fileToKeep = files[0].Path
-	case "prompt":
-		fmt.Println()
-		logInfo("--- Set %d (%s) ---", setIndex, formatBytes(getFileSize(files[0].Path)))
+	if strategy.prompt {
+		fmt.Fprintln(os.Stderr)
+		logInfo("--- Set %d (%s) ---", setIndex, formatBytes(setSize))
 		for i, f := range files {
 			logInfo("  [%d] %s (%s ago)", i+1, f.Path, time.Since(f.ModTime).Round(time.Second))
 		}
-		fmt.Printf("\033[34m❓ For set %d, enter the number of the file to KEEP [1-%d], or 's' to skip: \033[0m", setIndex, len(files))
+		fmt.Fprintf(os.Stderr, "\033[34m❓ For set %d, enter the number of the file to KEEP [1-%d], or 's' to skip: \033[0m", setIndex, len(files))
 		reader := bufio.NewReader(os.Stdin)
 		for {
-			response, _ := reader.ReadString('\n')
+			response, err := reader.ReadString('\n')
 			response = strings.TrimSpace(response)
 			if strings.ToLower(response) == "s" {
-				return nil, nil
+				return "", nil, nil
+			}
+			if err != nil && response == "" {
+				// The input ended, so no answer is coming: skip the set rather than ask forever.
+				fmt.Fprintln(os.Stderr)
+				logInfo("  -> No answer for set %d, skipping it.", setIndex)
+				return "", nil, nil
 			}
 			choice, err := strconv.Atoi(response)
 			if err == nil && choice >= 1 && choice <= len(files) {
 				fileToKeep = files[choice-1].Path
 				break
 			}
-			fmt.Printf("\033[31mInvalid input. Please enter a number between 1 and %d, or 's' to skip: \033[0m", len(files))
+			fmt.Fprintf(os.Stderr, "\033[31mInvalid input. Please enter a number between 1 and %d, or 's' to skip: \033[0m", len(files))
 		}
-	default:
-		return nil, fmt.Errorf("unknown duplicate keep strategy: '%s'", config.DuplicateKeep)
+	} else {
+		// Rank the files by the rules in order; the alphabetical order settles any remaining tie.
+		sort.SliceStable(files, func(i, j int) bool {
+			for _, rule := range strategy.rules {
+				if c := rule.compare(files[i], files[j]); c != 0 {
+					return c < 0
+				}
+			}
+			return files[i].Path < files[j].Path
+		})
+		fileToKeep = files[0].Path
 	}
 
 	for _, f := range files {
@@ -1216,7 +2291,87 @@ func processDuplicateSet(set []string, setIndex int) ([]string, error) {
 	if fileToKeep != "" {
 		logVerbose("  -> For set %d, keeping: %s", setIndex, fileToKeep)
 	}
-	return filesToDelete, nil
+	return fileToKeep, filesToDelete, nil
+}
+
+// pathDepth returns the number of path components in a path.
+func pathDepth(path string) int {
+	return strings.Count(filepath.Clean(path), string(filepath.Separator))
+}
+
+// pathMatchesGlob reports whether a path, or any of the directories containing it, matches a glob
+// pattern. This lets "/photos" or "/photos/*/originals" match every file stored below them.
+// A relative pattern such as "Downloads" or "*/copies" is matched against the trailing
+// components of each path, so it applies wherever that directory appears.
+func pathMatchesGlob(pattern, path string) bool {
+	pattern = filepath.Clean(pattern)
+	tail := -1
+	if !filepath.IsAbs(pattern) {
+		tail = strings.Count(pattern, string(filepath.Separator)) + 1
+	}
+	for p := path; ; p = filepath.Dir(p) {
+		candidate := p
+		if tail > 0 {
+			parts := strings.Split(filepath.ToSlash(p), "/")
+			if len(parts) > tail {
+				parts = parts[len(parts)-tail:]
+			}
+			candidate = filepath.FromSlash(strings.Join(parts, "/"))
+		}
+		if matched, _ := filepath.Match(pattern, candidate); matched {
+			return true
+		}
+		if parent := filepath.Dir(p); parent == p {
+			return false
+		}
+	}
+}
+
+// compareBool orders a true value before a false one, for use in keepRule.compare.
+func compareBool(a, b bool) int {
+	switch {
+	case a && !b:
+		return -1
+	case b && !a:
+		return 1
+	}
+	return 0
+}
+
+// printAgeReport prints the age histograms as a human-readable table per folder.
+func printAgeReport(dirs []string, reports map[string]*dirAgeReport) {
+	for _, dir := range dirs {
+		report := reports[dir]
+		var totalFiles, totalBytes int64
+		for i := range ageBuckets {
+			totalFiles += report.Modified.Files[i]
+			totalBytes += report.Modified.Bytes[i]
+		}
+		logInfo("\n📁 %s (%d files, %s)", dir, totalFiles, formatBytes(totalBytes))
+		logInfo("   %-8s %-26s %-26s", "Age", "Last Modified", "Last Accessed")
+		for i, bucket := range ageBuckets {
+			modified := fmt.Sprintf("%d files / %s", report.Modified.Files[i], formatBytes(report.Modified.Bytes[i]))
+			accessed := fmt.Sprintf("%d files / %s", report.Accessed.Files[i], formatBytes(report.Accessed.Bytes[i]))
+			logInfo("   %-8s %-26s %-26s", bucket.Label, modified, accessed)
+		}
+	}
+}
+
+// ownerColumns returns the owner and group names of a file for structured output.
+// Unknown IDs are shown numerically; platforms without owners yield empty strings.
+func ownerColumns(info os.FileInfo) (string, string) {
+	uid, gid, ok := fileOwnership(info)
+	if !ok {
+		return "", ""
+	}
+	owner, group := lookupUserName(uid), lookupGroupName(gid)
+	if owner == "" {
+		owner = strconv.FormatUint(uint64(uid), 10)
+	}
+	if group == "" {
+		group = strconv.FormatUint(uint64(gid), 10)
+	}
+	return owner, group
 }
 
 func getFileSize(path string) int64 {
@@ -1227,6 +2382,16 @@ func getFileSize(path string) int64 {
 	return info.Size()
 }
 
+// modTimeOf returns the modification time of a path in whole seconds, as in RFC 3339, or the
+// zero time if it cannot be read.
+func modTimeOf(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime().Truncate(time.Second)
+}
+
 // --- String Helpers & Sorters ---
 func getActionString() string {
 	if config.UseTrash {
@@ -1246,6 +2411,12 @@ func getActionStringPast() string {
 	}
 	return "Deleted"
 }
+func getActionName() string {
+	if config.UseTrash {
+		return "trash"
+	}
+	return "delete"
+}
 func getActionIcon() string {
 	if config.UseTrash {
 		return "♻️"
@@ -1253,6 +2424,14 @@ func getActionIcon() string {
 	return "🗑️"
 }
 
+func ageBucketLabels() []string {
+	labels := make([]string, len(ageBuckets))
+	for i, bucket := range ageBuckets {
+		labels[i] = bucket.Label
+	}
+	return labels
+}
+
 func stringSliceToSet(slice []string) map[string]struct{} {
 	set := make(map[string]struct{}, len(slice))
 	for _, item := range slice {
@@ -1273,9 +2452,9 @@ func sortResults(files []fileResult) {
 }
 
 // --- Summary Printers ---
-func printEmptyModeSummary(targetDir string) {
+func printEmptyModeSummary(targetDirs []string) {
 	logInfo("--- 🧹 Find Empty Folders Mode ---")
-	logInfo("🎯 Target Directory: %s", targetDir)
+	printTargetDirs(targetDirs)
 	if config.Recursive {
 		logInfo("🌲 Mode: Recursive Scan Enabled")
 	} else {
@@ -1285,43 +2464,118 @@ func printEmptyModeSummary(targetDir string) {
 	if len(config.IgnoreFiles) > 0 {
 		logInfo("🙈 Ignoring Files: %s", strings.Join(config.IgnoreFiles, ", "))
 	}
+	if config.JunkPreset != "" {
+		logInfo("🧦 Ignoring OS Junk: %s preset", config.JunkPreset)
+	}
+	if config.MaxJunkSize != "" {
+		logInfo("⚖️  Max Junk Size: %s per folder", config.MaxJunkSize)
+	}
+	if config.IncludeEmptyFiles {
+		logInfo("🪶 Zero-byte files: Treated as empty")
+	}
 	if config.OlderThanStr != "" {
-		logInfo("⏳ Age Filter: Only folders older than %s", config.OlderThanStr)
+		logInfo("⏳ Age Filter: Only folders older than %s (by %s)", config.OlderThanStr, config.AgeBy)
 	}
 	logInfo("----------------------------------\n")
 }
 
-func printFindModeSummary(targetDir string) {
+func printFindModeSummary(targetDirs []string) {
 	logInfo("--- 🔎 Find Files Mode ---")
-	logInfo("🎯 Target Directory: %s", targetDir)
+	printTargetDirs(targetDirs)
 	if config.FindDuplicates {
 		logInfo("🔎 Mode: Find Duplicates by Hash (%s)", config.HashAlgo)
 		logInfo("🤔 Keep Strategy: %s", config.DuplicateKeep)
+	} else if config.DuplicateDirs {
+		logInfo("🗂️  Mode: Find Duplicate Directories by Tree Hash (%s)", config.HashAlgo)
+		logInfo("🤔 Keep Strategy: %s", config.DuplicateKeep)
+	} else if config.SimilarImages {
+		logInfo("🖼️  Mode: Find Similar Images (%s, up to %d differing bits)", config.ImageHash, config.SimilarityThreshold)
+		logInfo("🤔 Keep Strategy: %s", config.DuplicateKeep)
 	} else {
-		logInfo("📜 Mode: Find by Size/Age")
+		if config.EmptyFiles {
+			logInfo("🪶 Mode: Find Empty Files")
+			if len(config.IgnoreFiles) > 0 {
+				logInfo("🙈 Allowed Empty Files: %s", strings.Join(config.IgnoreFiles, ", "))
+			}
+		} else {
+			logInfo("📜 Mode: Find by Size/Age")
+		}
 		if config.FilesOverStr != "" {
 			logInfo("📏 Size Filter: Files over %s", config.FilesOverStr)
 		}
+		if config.FilesUnderStr != "" {
+			logInfo("📏 Size Filter: Files under %s", config.FilesUnderStr)
+		}
+		if config.SizeRange != "" {
+			logInfo("📏 Size Filter: Files within %s", config.SizeRange)
+		}
 		if config.OlderThanStr != "" {
-			logInfo("⏳ Age Filter: Files older than %s", config.OlderThanStr)
+			logInfo("⏳ Age Filter: Files older than %s (by %s)", config.OlderThanStr, config.AgeBy)
 		}
+		if config.NewerThanStr != "" {
+			logInfo("⏳ Age Filter: Files newer than %s (by %s)", config.NewerThanStr, config.AgeBy)
+		}
+		if config.ModifiedBefore != "" {
+			logInfo("📅 Date Filter: Modified before %s", config.ModifiedBefore)
+		}
+		if config.ModifiedAfter != "" {
+			logInfo("📅 Date Filter: Modified after %s", config.ModifiedAfter)
+		}
+		if config.Where != "" {
+			logInfo("🧮 Expression Filter: %s", config.Where)
+		}
+		if config.Owner != "" || config.UID != "" {
+			logInfo("👤 Owner Filter: %s", strings.TrimSpace(config.Owner+" "+config.UID))
+		}
+		if config.Group != "" {
+			logInfo("👥 Group Filter: %s", config.Group)
+		}
+		if config.NoUser {
+			logInfo("👻 Owner Filter: Files without a known user")
+		}
+		if config.Perm != "" {
+			logInfo("🔐 Permission Filter: %s", config.Perm)
+		}
+		if config.WorldWritable {
+			logInfo("🔓 Permission Filter: World-writable files")
+		}
+		logInfo("🔗 Match: %s of the filters", config.MatchMode)
 	}
 	printCommonSummary(false)
 	logInfo("----------------------------------\n")
 }
 
-func printLargeModeSummary(targetDir string) {
+func printLargeModeSummary(targetDirs []string) {
 	logInfo("--- 📊 Find Large Folders Mode ---")
-	logInfo("🎯 Target Directory: %s", targetDir)
+	printTargetDirs(targetDirs)
 	logInfo("📈 Showing Top: %d folders", config.TopN)
 	printCommonSummary(true)
 	logInfo("----------------------------------\n")
 }
 
+func printReportModeSummary(targetDirs []string) {
+	logInfo("--- 📅 Age Report Mode ---")
+	printTargetDirs(targetDirs)
+	logInfo("🪣 Buckets: %s", strings.Join(ageBucketLabels(), ", "))
+	printCommonSummary(true)
+	logInfo("----------------------------------\n")
+}
+
+func printTargetDirs(targetDirs []string) {
+	if len(targetDirs) == 1 {
+		logInfo("🎯 Target Directory: %s", targetDirs[0])
+		return
+	}
+	logInfo("🎯 Target Directories: %s", strings.Join(targetDirs, ", "))
+}
+
 func printCommonSummary(isReadOnly bool) {
 	if configFileUsed != "" {
 		logInfo("⚙️ Using Config: %s", configFileUsed)
 	}
+	if config.Profile != "" {
+		logInfo("🧩 Using Profile: %s", config.Profile)
+	}
 	if !isReadOnly {
 		if config.DryRun {
 			logInfo("🧪 Action: Dry Run")
@@ -1351,32 +2605,143 @@ func printCommonSummary(isReadOnly bool) {
 // --- State Isolation for Command Runs ---
 // runContext isolates state for a single command execution to prevent conflicts.
 type runContext struct {
-	olderThan      time.Time
-	filesOverBytes int64
-	excludeRegex   *regexp.Regexp
-	excludeDirSet  map[string]struct{}
+	olderThan     time.Time
+	ageBy         string
+	usesAge       bool            // Whether a filter tests the timestamp selected by --age-by.
+	criteria      []fileCriterion // Size, age and date filters, combined according to --match.
+	filters       []fileCriterion // Filters every file must pass whatever --match says: --where, ownership and permissions.
+	matchAll      bool
+	excludeRegex  *regexp.Regexp
+	excludeDirSet map[string]struct{}
+	ignore        *ignoreMatcher // Files that don't keep a folder from being empty (--ignore-files, --junk-preset).
+	maxJunkSize   int64          // Limit for the total size of ignored files in an empty folder; -1 if unlimited.
+}
+
+// fileCriterion is a single filter of the 'find' command, such as --files-over or --modified-before.
+type fileCriterion struct {
+	name  string
+	match func(path string, info os.FileInfo) bool
 }
 
 // newRunContext creates a new isolated context from the global config.
 func newRunContext() (*runContext, error) {
 	ctx := &runContext{
+		ageBy:         strings.ToLower(config.AgeBy),
+		matchAll:      strings.ToLower(config.MatchMode) == "all",
 		excludeDirSet: stringSliceToSet(config.ExcludeDirs),
 	}
 	var err error
 
+	if ctx.ageBy == "" {
+		ctx.ageBy = "mtime"
+	}
+
+	junk, err := junkPreset(strings.ToLower(config.JunkPreset))
+	if err != nil {
+		return nil, err
+	}
+	if ctx.ignore, err = newIgnoreMatcher(append(append([]string{}, config.IgnoreFiles...), junk...)); err != nil {
+		return nil, err
+	}
+	if config.MaxDeleteSize != "" {
+		if _, err := parseSize(config.MaxDeleteSize); err != nil {
+			return nil, fmt.Errorf("invalid size for --max-delete-size: %w", err)
+		}
+	}
+	ctx.maxJunkSize = -1
+	if config.MaxJunkSize != "" {
+		if ctx.maxJunkSize, err = parseSize(config.MaxJunkSize); err != nil {
+			return nil, fmt.Errorf("invalid size for --max-junk-size: %w", err)
+		}
+	}
+	if !contains([]string{"mtime", "atime", "ctime", "btime"}, ctx.ageBy) {
+		return nil, fmt.Errorf("invalid value for --age-by: %q. Allowed values are: [mtime, atime, ctime, btime]", config.AgeBy)
+	}
+
 	if config.OlderThanStr != "" {
 		dur, err := parseDuration(config.OlderThanStr)
 		if err != nil {
 			return nil, fmt.Errorf("invalid duration for --older-than: %w", err)
 		}
 		ctx.olderThan = time.Now().Add(-dur)
+		cutoff := ctx.olderThan
+		ctx.addTimeCriterion("older-than", func(t time.Time) bool { return t.Before(cutoff) })
+	}
+
+	if config.NewerThanStr != "" {
+		dur, err := parseDuration(config.NewerThanStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for --newer-than: %w", err)
+		}
+		cutoff := time.Now().Add(-dur)
+		ctx.addTimeCriterion("newer-than", func(t time.Time) bool { return t.After(cutoff) })
+	}
+
+	if config.ModifiedBefore != "" {
+		date, err := parseDate(config.ModifiedBefore)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date for --modified-before: %w", err)
+		}
+		ctx.criteria = append(ctx.criteria, fileCriterion{"modified-before", func(path string, info os.FileInfo) bool {
+			return info.ModTime().Before(date)
+		}})
+	}
+
+	if config.ModifiedAfter != "" {
+		date, err := parseDate(config.ModifiedAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date for --modified-after: %w", err)
+		}
+		ctx.criteria = append(ctx.criteria, fileCriterion{"modified-after", func(path string, info os.FileInfo) bool {
+			return info.ModTime().After(date)
+		}})
 	}
 
 	if config.FilesOverStr != "" {
-		ctx.filesOverBytes, err = parseSize(config.FilesOverStr)
+		minBytes, err := parseSize(config.FilesOverStr)
 		if err != nil {
 			return nil, fmt.Errorf("invalid size for --files-over: %w", err)
 		}
+		ctx.criteria = append(ctx.criteria, fileCriterion{"files-over", func(path string, info os.FileInfo) bool {
+			return info.Size() >= minBytes
+		}})
+	}
+
+	if config.FilesUnderStr != "" {
+		maxBytes, err := parseSize(config.FilesUnderStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size for --files-under: %w", err)
+		}
+		ctx.criteria = append(ctx.criteria, fileCriterion{"files-under", func(path string, info os.FileInfo) bool {
+			return info.Size() < maxBytes
+		}})
+	}
+
+	if config.SizeRange != "" {
+		minBytes, maxBytes, err := parseSizeRange(config.SizeRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range for --size: %w", err)
+		}
+		// Both bounds form a single criterion, so '--match any' never splits a range into "over OR under".
+		ctx.criteria = append(ctx.criteria, fileCriterion{"size", func(path string, info os.FileInfo) bool {
+			return info.Size() >= minBytes && (maxBytes < 0 || info.Size() <= maxBytes)
+		}})
+	}
+
+	if config.Where != "" {
+		expr, err := parseWhere(config.Where)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression for --where: %w", err)
+		}
+		ctx.usesAge = ctx.usesAge || whereUsesField(expr, "age")
+		// The expression has its own && and ||, so it narrows the selection instead of widening it.
+		ctx.filters = append(ctx.filters, fileCriterion{"where", func(path string, info os.FileInfo) bool {
+			return expr.eval(&whereFile{path: path, info: info, rc: ctx})
+		}})
+	}
+
+	if err := ctx.addOwnershipFilters(); err != nil {
+		return nil, err
 	}
 
 	if config.ExcludePattern != "" {
@@ -1389,6 +2754,129 @@ func newRunContext() (*runContext, error) {
 	return ctx, nil
 }
 
+// addOwnershipFilters registers the --owner, --group, --uid, --nouser, --perm and --world-writable
+// filters. Like --where, they narrow the selection: 'find -O 30d --owner ci' finds old files of ci,
+// not old files and every file of ci.
+func (rc *runContext) addOwnershipFilters() error {
+	addIDFilter := func(name string, wantUser bool, id uint32) {
+		rc.filters = append(rc.filters, fileCriterion{name, func(path string, info os.FileInfo) bool {
+			uid, gid, ok := fileOwnership(info)
+			if wantUser {
+				return ok && uid == id
+			}
+			return ok && gid == id
+		}})
+	}
+
+	if config.Owner != "" {
+		uid, err := lookupUserID(config.Owner)
+		if err != nil {
+			return fmt.Errorf("invalid value for --owner: %w", err)
+		}
+		addIDFilter("owner", true, uid)
+	}
+	if config.UID != "" {
+		uid, err := strconv.ParseUint(config.UID, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid value for --uid: %q is not a numeric UID", config.UID)
+		}
+		addIDFilter("uid", true, uint32(uid))
+	}
+	if config.Group != "" {
+		gid, err := lookupGroupID(config.Group)
+		if err != nil {
+			return fmt.Errorf("invalid value for --group: %w", err)
+		}
+		addIDFilter("group", false, gid)
+	}
+	if config.NoUser {
+		rc.filters = append(rc.filters, fileCriterion{"nouser", func(path string, info os.FileInfo) bool {
+			uid, _, ok := fileOwnership(info)
+			return ok && lookupUserName(uid) == ""
+		}})
+	}
+	if config.Perm != "" {
+		test, err := parsePerm(config.Perm)
+		if err != nil {
+			return fmt.Errorf("invalid value for --perm: %w", err)
+		}
+		rc.filters = append(rc.filters, fileCriterion{"perm", func(path string, info os.FileInfo) bool {
+			return test(info.Mode().Perm())
+		}})
+	}
+	if config.WorldWritable {
+		rc.filters = append(rc.filters, fileCriterion{"world-writable", func(path string, info os.FileInfo) bool {
+			return info.Mode().Perm()&0o002 != 0
+		}})
+	}
+	return nil
+}
+
+// addTimeCriterion registers a criterion that tests the timestamp selected by --age-by.
+// Files for which that timestamp is unavailable never match.
+func (rc *runContext) addTimeCriterion(name string, test func(time.Time) bool) {
+	rc.usesAge = true
+	rc.criteria = append(rc.criteria, fileCriterion{name, func(path string, info os.FileInfo) bool {
+		t := rc.fileTime(path, info)
+		if t.IsZero() {
+			logVerbose("No %s available for %s, skipping --%s check.", rc.ageBy, path, name)
+			return false
+		}
+		return test(t)
+	}})
+}
+
+// matchesCriteria requires every filter and combines the criteria according to --match.
+// A run without any criteria matches every file that passes the filters.
+func (rc *runContext) matchesCriteria(path string, info os.FileInfo) bool {
+	for _, f := range rc.filters {
+		if !f.match(path, info) {
+			return false
+		}
+	}
+	if len(rc.criteria) == 0 {
+		return true
+	}
+	for _, c := range rc.criteria {
+		if c.match(path, info) != rc.matchAll {
+			// In 'all' mode the first miss decides; in 'any' mode the first hit does.
+			return !rc.matchAll
+		}
+	}
+	return rc.matchAll
+}
+
+// fileTime returns the timestamp selected by --age-by for a file.
+// It returns the zero time if the platform or filesystem does not record that timestamp.
+func (rc *runContext) fileTime(path string, info os.FileInfo) time.Time {
+	times := statTimes(info)
+	switch rc.ageBy {
+	case "atime":
+		return times.Accessed
+	case "ctime":
+		return times.Changed
+	case "btime":
+		if times.Born.IsZero() {
+			return statxBirthTime(path)
+		}
+		return times.Born
+	default:
+		return times.Modified
+	}
+}
+
+// warnIfAtimeUnreliable tells the user when access-time filtering is requested on a 'noatime' mount.
+func warnIfAtimeUnreliable(targetDirs []string, rc *runContext) {
+	if rc.ageBy != "atime" || !rc.usesAge {
+		return
+	}
+	for _, targetDir := range targetDirs {
+		if isNoatimeMount(targetDir) {
+			logInfo("⚠️  %s is on a filesystem mounted with 'noatime'; access times are not updated, so --age-by atime is unreliable.", targetDir)
+		}
+	}
+}
+
 // shouldExclude checks if a path should be skipped based on the isolated run context.
 func (rc *runContext) shouldExclude(path string) bool {
 	pathForMatching := filepath.ToSlash(path)
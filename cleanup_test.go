@@ -0,0 +1,280 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSizeRange(t *testing.T) {
+	tests := []struct {
+		in       string
+		min, max int64
+		wantErr  bool
+	}{
+		{in: "10MB..1GB", min: 10 << 20, max: 1 << 30},
+		{in: "10MB..", min: 10 << 20, max: -1},
+		{in: "..4KB", min: 0, max: 4 << 10},
+		{in: " 1K .. 1K ", min: 1 << 10, max: 1 << 10},
+		{in: "1GB..10MB", wantErr: true},
+		{in: "..", wantErr: true},
+		{in: "10MB", wantErr: true},
+		{in: "lots..1GB", wantErr: true},
+		{in: "1GB..more", wantErr: true},
+	}
+	for _, tt := range tests {
+		min, max, err := parseSizeRange(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseSizeRange(%q) = %d, %d, want an error", tt.in, min, max)
+			}
+			continue
+		}
+		if err != nil || min != tt.min || max != tt.max {
+			t.Errorf("parseSizeRange(%q) = %d, %d, %v, want %d, %d", tt.in, min, max, err, tt.min, tt.max)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2025-01-31", want: time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local)},
+		{in: "2025-01-31T15:04:05", want: time.Date(2025, 1, 31, 15, 4, 5, 0, time.Local)},
+		{in: "2025-01-31 15:04:05", want: time.Date(2025, 1, 31, 15, 4, 5, 0, time.Local)},
+		{in: "2025-01-31T15:04", want: time.Date(2025, 1, 31, 15, 4, 0, 0, time.Local)},
+		{in: "2025-01-31T15:04:05Z", want: time.Date(2025, 1, 31, 15, 4, 5, 0, time.UTC)},
+		{in: "", wantErr: true},
+		{in: "2025-13-01", wantErr: true},
+		{in: "31.01.2025", wantErr: true},
+		{in: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDate(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDate(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParsePerm(t *testing.T) {
+	tests := []struct {
+		in   string
+		perm map[os.FileMode]bool
+	}{
+		{"644", map[os.FileMode]bool{0o644: true, 0o664: false, 0o600: false}},
+		{"-644", map[os.FileMode]bool{0o644: true, 0o664: true, 0o755: true, 0o555: false, 0o600: false}},
+		{"/022", map[os.FileMode]bool{0o644: false, 0o664: true, 0o646: true, 0o777: true}},
+		{"0", map[os.FileMode]bool{0: true, 0o400: false}},
+	}
+	for _, tt := range tests {
+		match, err := parsePerm(tt.in)
+		if err != nil {
+			t.Fatalf("parsePerm(%q): %v", tt.in, err)
+		}
+		for perm, want := range tt.perm {
+			if got := match(perm); got != want {
+				t.Errorf("parsePerm(%q) on %04o = %v, want %v", tt.in, perm, got, want)
+			}
+		}
+	}
+
+	for _, in := range []string{"", "-", "/", "648", "rwxr-xr-x", "1777", "+644", "0x1ff"} {
+		if _, err := parsePerm(in); err == nil {
+			t.Errorf("parsePerm(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	defer func(old map[string]Profile) { configProfiles = old }(configProfiles)
+	configProfiles = map[string]Profile{
+		"base":    {Command: "find", Paths: []string{"/srv"}, Settings: map[string]interface{}{"dry-run": true, "older-than": "30d"}},
+		"logs":    {Inherits: "base", Settings: map[string]interface{}{"older-than": "7d"}},
+		"nightly": {Inherits: "logs", Paths: []string{"/var/log"}},
+		"self":    {Inherits: "self"},
+		"a":       {Inherits: "b"},
+		"b":       {Inherits: "a"},
+		"orphan":  {Inherits: "missing"},
+	}
+
+	profile, err := resolveProfile("nightly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Command != "find" || len(profile.Paths) != 1 || profile.Paths[0] != "/var/log" || profile.Inherits != "logs" {
+		t.Errorf("resolveProfile(nightly) = %+v", profile)
+	}
+	if profile.Settings["older-than"] != "7d" || profile.Settings["dry-run"] != true {
+		t.Errorf("settings = %v, want older-than from logs and dry-run from base", profile.Settings)
+	}
+	if configProfiles["base"].Settings["older-than"] != "30d" {
+		t.Error("resolving a profile changed the profile it inherits from")
+	}
+
+	for _, name := range []string{"self", "a", "orphan", "unknown"} {
+		if _, err := resolveProfile(name); err == nil {
+			t.Errorf("resolveProfile(%q) succeeded, want an error", name)
+		}
+	}
+}
+
+// duplicateTree creates copies of the same file with different ages and depths below two roots.
+func duplicateTree(t *testing.T) (root1, root2 string, paths map[string]string) {
+	t.Helper()
+	base := t.TempDir()
+	root1, root2 = filepath.Join(base, "root1"), filepath.Join(base, "root2")
+	paths = map[string]string{
+		"a": filepath.Join(root1, "photos", "a.jpg"),
+		"b": filepath.Join(root1, "b.jpg"),
+		"c": filepath.Join(root2, "copies", "deep", "c.jpg"),
+		"d": filepath.Join(root2, "d.jpg"),
+	}
+	years := map[string]int{"a": 2020, "b": 2024, "c": 2022, "d": 2019}
+	for name, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("same"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modified := time.Date(years[name], 6, 1, 0, 0, 0, 0, time.UTC)
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root1, root2, paths
+}
+
+func TestKeepStrategy(t *testing.T) {
+	defer func(old Config) { config = old }(config)
+	config.Force = true
+	root1, root2, paths := duplicateTree(t)
+	set := []string{paths["a"], paths["b"], paths["c"], paths["d"]}
+	roots := []string{root1, root2}
+
+	tests := []struct {
+		spec string
+		keep string
+	}{
+		{"newest", "b"},
+		{"oldest", "d"},
+		{"first", "b"}, // root1/b.jpg sorts before root1/photos/a.jpg.
+		{"deepest", "c"},
+		{"shortest-path", "b"},
+		// Ties are broken by the next rule, and finally alphabetically.
+		{"shallowest", "b"},
+		{"shallowest,oldest", "d"},
+		{"shallowest,newest", "b"},
+		{"prefer-path=photos,newest", "a"},
+		{"prefer-path=" + root2 + ",oldest", "d"},
+		{"prefer-path=*/copies,oldest", "c"},
+		{"avoid-path=copies,deepest", "a"},
+		{"avoid-path=" + root1 + ",newest", "c"},
+		{"prefer-root=" + root2 + ",newest", "c"},
+		{"prefer-root=" + root1 + ", oldest", "a"},
+		{"prompt", "b"}, // --force has nobody to ask, so the alphabetical order decides.
+	}
+	for _, tt := range tests {
+		strategy, err := parseKeepStrategy(tt.spec, roots)
+		if err != nil {
+			t.Fatalf("parseKeepStrategy(%q): %v", tt.spec, err)
+		}
+		keep, toDelete, err := processDuplicateSet(set, 1, 4, strategy)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.spec, err)
+		}
+		if keep != paths[tt.keep] {
+			t.Errorf("--keep %s kept %s, want %s", tt.spec, keep, paths[tt.keep])
+		}
+		if len(toDelete) != len(set)-1 || contains(toDelete, keep) {
+			t.Errorf("--keep %s deletes %v", tt.spec, toDelete)
+		}
+	}
+}
+
+func TestKeepStrategyErrors(t *testing.T) {
+	roots := []string{"/srv/a", "/srv/b"}
+	for _, spec := range []string{"biggest", "newest=1", "prefer-path", "avoid-path=[", "prefer-root=/elsewhere", "prompt,newest", "newest,prompt"} {
+		if _, err := parseKeepStrategy(spec, roots); err == nil {
+			t.Errorf("parseKeepStrategy(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+// TestKeepPromptFallback checks when --keep prompt asks and when it falls back to 'first'.
+func TestKeepPromptFallback(t *testing.T) {
+	defer func(old Config, isTerminal func() bool) { config, stdinIsTerminal = old, isTerminal }(config, stdinIsTerminal)
+	tests := []struct {
+		terminal, force bool
+		export          string
+		prompt          bool
+	}{
+		{terminal: true, prompt: true},
+		{terminal: true, force: true},
+		{terminal: true, export: "dupes.txt"},
+		{terminal: false},
+	}
+	for _, tt := range tests {
+		stdinIsTerminal = func() bool { return tt.terminal }
+		config.Force, config.ExportDecisions = tt.force, tt.export
+		strategy, err := parseKeepStrategy("prompt", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strategy.prompt != tt.prompt || (!tt.prompt && len(strategy.rules) != 1) {
+			t.Errorf("terminal=%v force=%v export=%q: prompt = %v with %d rule(s), want prompt = %v", tt.terminal, tt.force, tt.export, strategy.prompt, len(strategy.rules), tt.prompt)
+		}
+	}
+}
+
+func TestPathMatchesGlob(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"/photos", "/photos/2020/a.jpg", true},
+		{"/photos", "/photoshop/a.jpg", false},
+		{"/photos/*/originals", "/photos/2020/originals/a.jpg", true},
+		{"/photos/*/originals", "/photos/2020/edits/a.jpg", false},
+		{"Downloads", "/home/me/Downloads/x.zip", true},
+		{"Downloads", "/home/me/MyDownloads/x.zip", false},
+		{"*/copies", "/a/b/copies/x", true},
+		{"*.bak", "/a/b/x.bak", true},
+		{"copies/x", "/a/copies/x", true},
+	}
+	for _, tt := range tests {
+		pattern, path := filepath.FromSlash(tt.pattern), filepath.FromSlash(tt.path)
+		if got := pathMatchesGlob(pattern, path); got != tt.want {
+			t.Errorf("pathMatchesGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDuplicateGroupDecide(t *testing.T) {
+	group := &duplicateGroup{Paths: []string{"/a", "/b", "/c"}, Size: 10}
+	group.decide("/b", []string{"/a", "/c"}, func(string) int64 { return 10 })
+	if group.Keep != "/b" || group.Reclaimable != 20 || len(group.Delete) != 2 {
+		t.Errorf("decide = keep %s, reclaim %d, delete %v", group.Keep, group.Reclaimable, group.Delete)
+	}
+	// A copy that is neither kept nor deleted, e.g. one containing a kept directory, doesn't
+	// change which copy is the keeper.
+	group.decide("/a", []string{"/c"}, func(string) int64 { return 10 })
+	if group.Keep != "/a" || group.Reclaimable != 10 {
+		t.Errorf("decide = keep %s, reclaim %d, want /a and 10", group.Keep, group.Reclaimable)
+	}
+	group.decide("", nil, nil)
+	if group.Keep != "" || group.Reclaimable != 0 {
+		t.Errorf("a skipped set keeps %q and reclaims %d", group.Keep, group.Reclaimable)
+	}
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bufio"         // For reading the text format line by line.
+	"bytes"         // For detecting the JSON format.
+	"encoding/json" // For the JSON format.
+	"fmt"           // For formatting the text format and error messages.
+	"math/bits"     // For comparing perceptual hashes in images mode.
+	"os"            // For reading and writing the decisions file and checking files.
+	"path/filepath" // For choosing the format by extension and walking directories.
+	"strconv"       // For parsing sizes in the text format.
+	"strings"       // For parsing the text format.
+	"time"          // For recording and comparing modification times.
+)
+
+// --- Duplicate Decisions Files ---
+// Instead of answering a prompt for every duplicate set, the decisions can be made in a file:
+//
+//	cleanup find -D --export-decisions dupes.txt ~/Pictures   # scan and write the file
+//	$EDITOR dupes.txt                                          # move the keep: markers
+//	cleanup find --decisions dupes.txt                         # apply exactly those choices
+//
+// Every copy is recorded with its size and modification time, and nothing in a set is removed
+// if any of its copies changed after the file was written. Files ending in .json use JSON,
+// everything else the text format written by writeDecisionsText.
+
+// decisionsFile is the content of a decisions file.
+type decisionsFile struct {
+	Version   int             `json:"version"`
+	Mode      string          `json:"mode"`                           // files, dirs or images: the kind of duplicates.
+	HashAlgo  string          `json:"hash_algo"`                      // Algorithm of the set hashes: sha256, sha1 or md5, or dhash or phash in images mode.
+	Threshold *int            `json:"similarity_threshold,omitempty"` // --similarity-threshold of an images mode scan.
+	Roots     []string        `json:"roots"`                          // Target paths of the scan.
+	Created   time.Time       `json:"created"`
+	Groups    []decisionGroup `json:"groups"`
+}
+
+// decisionGroup is one duplicate set. Keep names the copy to keep; a set without one is skipped.
+type decisionGroup struct {
+	Group int            `json:"group"`
+	Hash  string         `json:"hash"`
+	Keep  string         `json:"keep"`
+	Files []decisionFile `json:"files"`
+}
+
+// decisionFile is the state of one copy when the file was written.
+type decisionFile struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// decisionsVersion is the version of the decisions file format.
+const decisionsVersion = 1
+
+// finishDuplicateGroups reports the duplicate sets and then either exports them as a decisions
+// file or removes the copies that were not kept. sizeOf returns the size of one copy.
+func finishDuplicateGroups(mode, itemType string, groups []*duplicateGroup, targetDirs []string, sizeOf func(string) int64) error {
+	outputDuplicateGroups(groups, targetDirs)
+	if config.ExportDecisions != "" {
+		return writeDecisions(config.ExportDecisions, mode, groups, targetDirs, sizeOf)
+	}
+
+	var pathsToDelete []string
+	sizes := make(map[string]int64)
+	for _, group := range groups {
+		pathsToDelete = append(pathsToDelete, group.Delete...)
+		for p, size := range group.sizes {
+			sizes[p] = size
+		}
+	}
+	return handleDeletion(itemType, pathsToDelete, sizes)
+}
+
+// writeDecisions writes the duplicate sets with the keeper chosen by the keep strategy.
+func writeDecisions(path, mode string, groups []*duplicateGroup, targetDirs []string, sizeOf func(string) int64) error {
+	doc := decisionsFile{Version: decisionsVersion, Mode: mode, HashAlgo: config.HashAlgo, Roots: targetDirs, Created: time.Now()}
+	if mode == "images" {
+		threshold := config.SimilarityThreshold
+		doc.HashAlgo, doc.Threshold = config.ImageHash, &threshold
+	}
+	for i, g := range groups {
+		group := decisionGroup{Group: i + 1, Hash: g.Hash, Keep: g.Keep}
+		for _, p := range g.Paths {
+			info, err := os.Stat(p)
+			if err != nil {
+				return fmt.Errorf("could not stat %s: %w", p, err)
+			}
+			group.Files = append(group.Files, decisionFile{Path: p, Size: sizeOf(p), Modified: info.ModTime()})
+		}
+		doc.Groups = append(doc.Groups, group)
+	}
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var err error
+		if data, err = json.MarshalIndent(doc, "", "  "); err != nil {
+			return fmt.Errorf("failed to generate decisions file: %w", err)
+		}
+	} else {
+		data = []byte(writeDecisionsText(doc))
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("could not write decisions file: %w", err)
+	}
+	if len(groups) > 0 {
+		markItemsFound()
+	}
+	logInfo("\n📝 Wrote %d set(s) to %s. Nothing was deleted.", len(groups), path)
+	logInfo("   Edit the keep: markers and apply them with: cleanup find --decisions %s", path)
+	return nil
+}
+
+// writeDecisionsText renders the text format, e.g.:
+//
+//	mode: files
+//	hash-algo: sha256
+//	root: /home/me/Pictures
+//
+//	set 1 hash=9f86d0...
+//	keep:	52311	2025-06-01T10:00:00Z	/home/me/Pictures/a.jpg
+//	delete:	52311	2025-06-03T18:12:45Z	/home/me/Pictures/copy of a.jpg
+func writeDecisionsText(doc decisionsFile) string {
+	var sb strings.Builder
+	sb.WriteString("# Duplicate decisions written by 'cleanup find' on " + doc.Created.Format(time.RFC3339) + ".\n")
+	sb.WriteString("# Every copy is listed as MARKER<TAB>SIZE<TAB>MODIFIED<TAB>PATH.\n")
+	sb.WriteString("# Mark the copy to keep with 'keep:' and the copies to remove with 'delete:'.\n")
+	sb.WriteString("# A set without a 'keep:' line is skipped. Sets with changed files are skipped too.\n")
+	sb.WriteString("# Apply with: cleanup find --decisions FILE\n\n")
+	fmt.Fprintf(&sb, "mode: %s\nhash-algo: %s\n", doc.Mode, doc.HashAlgo)
+	if doc.Threshold != nil {
+		fmt.Fprintf(&sb, "similarity-threshold: %d\n", *doc.Threshold)
+	}
+	for _, root := range doc.Roots {
+		fmt.Fprintf(&sb, "root: %s\n", root)
+	}
+	for _, g := range doc.Groups {
+		fmt.Fprintf(&sb, "\nset %d hash=%s\n", g.Group, g.Hash)
+		for _, f := range g.Files {
+			marker := "delete:"
+			if f.Path == g.Keep {
+				marker = "keep:"
+			}
+			fmt.Fprintf(&sb, "%s\t%d\t%s\t%s\n", marker, f.Size, f.Modified.Format(time.RFC3339Nano), f.Path)
+		}
+	}
+	return sb.String()
+}
+
+// loadDecisions reads a decisions file in either format.
+func loadDecisions(path string) (*decisionsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read decisions file: %w", err)
+	}
+
+	var doc *decisionsFile
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		doc = &decisionsFile{}
+		if err := json.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("invalid decisions file %s: %w", path, err)
+		}
+		if doc.Version > decisionsVersion {
+			return nil, fmt.Errorf("decisions file %s has version %d, but this version of cleanup only supports up to %d", path, doc.Version, decisionsVersion)
+		}
+	} else if doc, err = parseDecisionsText(string(data)); err != nil {
+		return nil, fmt.Errorf("invalid decisions file %s: %w", path, err)
+	}
+
+	if !contains([]string{"files", "dirs", "images"}, doc.Mode) {
+		return nil, fmt.Errorf("invalid decisions file %s: unknown mode %q. Allowed values are: [files, dirs, images]", path, doc.Mode)
+	}
+	if doc.Mode == "images" && !contains([]string{"dhash", "phash"}, doc.HashAlgo) {
+		return nil, fmt.Errorf("invalid decisions file %s: unknown image hash %q. Allowed values are: [dhash, phash]", path, doc.HashAlgo)
+	}
+	for _, g := range doc.Groups {
+		if g.Keep == "" {
+			continue
+		}
+		found := false
+		for _, f := range g.Files {
+			found = found || f.Path == g.Keep
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid decisions file %s: set %d keeps %s, which is not one of its copies", path, g.Group, g.Keep)
+		}
+	}
+	return doc, nil
+}
+
+// parseDecisionsText parses the text format written by writeDecisionsText.
+func parseDecisionsText(text string) (*decisionsFile, error) {
+	doc := &decisionsFile{Version: decisionsVersion}
+	var group *decisionGroup
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if group == nil {
+			if key, value, ok := strings.Cut(trimmed, ":"); ok && !strings.HasPrefix(trimmed, "set ") {
+				switch strings.TrimSpace(key) {
+				case "mode":
+					doc.Mode = strings.TrimSpace(value)
+				case "hash-algo":
+					doc.HashAlgo = strings.TrimSpace(value)
+				case "similarity-threshold":
+					threshold, err := strconv.Atoi(strings.TrimSpace(value))
+					if err != nil {
+						return nil, fmt.Errorf("line %d: invalid similarity threshold %q", lineNo, value)
+					}
+					doc.Threshold = &threshold
+				case "root":
+					doc.Roots = append(doc.Roots, strings.TrimSpace(value))
+				default:
+					return nil, fmt.Errorf("line %d: unknown setting %q", lineNo, key)
+				}
+				continue
+			}
+		}
+
+		if strings.HasPrefix(trimmed, "set ") {
+			fields := strings.Fields(trimmed)
+			number, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid set number %q", lineNo, fields[1])
+			}
+			doc.Groups = append(doc.Groups, decisionGroup{Group: number})
+			group = &doc.Groups[len(doc.Groups)-1]
+			for _, field := range fields[2:] {
+				if hash, ok := strings.CutPrefix(field, "hash="); ok {
+					group.Hash = hash
+				}
+			}
+			continue
+		}
+
+		if group == nil {
+			return nil, fmt.Errorf("line %d: expected 'set N' before the first copy", lineNo)
+		}
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("line %d: expected MARKER<TAB>SIZE<TAB>MODIFIED<TAB>PATH", lineNo)
+		}
+		marker := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(parts[0])), ":")
+		if marker != "keep" && marker != "delete" {
+			return nil, fmt.Errorf("line %d: unknown marker %q, use 'keep:' or 'delete:'", lineNo, parts[0])
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid size %q", lineNo, parts[1])
+		}
+		modified, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(parts[2]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid modification time %q", lineNo, parts[2])
+		}
+		file := decisionFile{Path: parts[3], Size: size, Modified: modified}
+		if marker == "keep" {
+			if group.Keep != "" {
+				return nil, fmt.Errorf("line %d: set %d already keeps %s; mark only one copy per set with 'keep:'", lineNo, group.Group, group.Keep)
+			}
+			group.Keep = file.Path
+		}
+		group.Files = append(group.Files, file)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// applyDecisions removes the copies a decisions file marks for deletion, after checking that
+// no copy of the set changed since the file was written.
+func applyDecisions(path string) error {
+	doc, err := loadDecisions(path)
+	if err != nil {
+		return err
+	}
+	logInfo("--- 📝 Apply Duplicate Decisions ---")
+	logInfo("📄 Decisions File: %s (%s, %d sets)", path, doc.Mode, len(doc.Groups))
+	printTargetDirs(doc.Roots)
+	printCommonSummary(false)
+	logInfo("----------------------------------\n")
+	// The hashes in the file can only be checked with the algorithm that made them.
+	switch {
+	case doc.Mode == "images":
+		config.ImageHash = doc.HashAlgo
+		if doc.Threshold != nil {
+			config.SimilarityThreshold = *doc.Threshold
+		}
+	case doc.HashAlgo != "":
+		config.HashAlgo = doc.HashAlgo
+	}
+
+	var groups []*duplicateGroup
+	var deleted, kept []string
+	sizes := make(map[string]int64)
+	for _, g := range doc.Groups {
+		group := &duplicateGroup{Hash: g.Hash}
+		for _, f := range g.Files {
+			group.Paths = append(group.Paths, f.Path)
+			sizes[f.Path] = f.Size
+		}
+		if len(g.Files) > 0 {
+			group.Size = g.Files[0].Size
+		}
+		groups = append(groups, group)
+		if g.Keep == "" {
+			logVerbose("Skipping set %d: no copy is marked keep:", g.Group)
+			continue
+		}
+		if err := validateDecisionGroup(doc, g); err != nil {
+			addError(fmt.Errorf("skipping set %d: %w", g.Group, err))
+			continue
+		}
+
+		var toDelete []string
+		for _, f := range g.Files {
+			switch {
+			case f.Path == g.Keep:
+			case isWithinAny(deleted, f.Path):
+				// Already removed together with a directory of an earlier set.
+			case containsAny(f.Path, append(kept, g.Keep)):
+				logVerbose("  -> Not removing %s: it contains a kept copy", f.Path)
+			default:
+				toDelete = append(toDelete, f.Path)
+			}
+		}
+		kept = append(kept, g.Keep)
+		deleted = append(deleted, toDelete...)
+		group.decide(g.Keep, toDelete, func(p string) int64 { return sizes[p] })
+		logVerbose("  -> For set %d, keeping: %s", g.Group, g.Keep)
+	}
+
+	itemType := map[string]string{"files": "duplicate files", "dirs": "duplicate directories", "images": "similar images"}[doc.Mode]
+	return finishDuplicateGroups(doc.Mode, itemType, groups, doc.Roots, func(p string) int64 { return sizes[p] })
+}
+
+// validateDecisionGroup checks that every copy of a set still exists with the recorded size and
+// modification time, and that its content still matches the set's hash: the content hash in
+// files mode, the tree hash in dirs mode and, within the similarity threshold, the perceptual
+// hash in images mode.
+func validateDecisionGroup(doc *decisionsFile, g decisionGroup) error {
+	if g.Hash == "" {
+		return fmt.Errorf("the set has no hash to check its copies against")
+	}
+	for _, f := range g.Files {
+		info, err := os.Stat(f.Path)
+		if err != nil {
+			return fmt.Errorf("could not stat %s: %w", f.Path, err)
+		}
+		if !info.ModTime().Equal(f.Modified) {
+			return fmt.Errorf("%s was modified after the decisions file was written", f.Path)
+		}
+
+		size, matches := info.Size(), false
+		switch doc.Mode {
+		case "files":
+			hash, err := hashFile(f.Path)
+			if err != nil {
+				return err
+			}
+			matches = hash == g.Hash
+		case "dirs":
+			tree, err := rehashDirTree(f.Path)
+			if err != nil {
+				return err
+			}
+			size, matches = tree.Size, tree.Hash == g.Hash
+		case "images":
+			want, err := strconv.ParseUint(g.Hash, 16, 64)
+			if err != nil {
+				return fmt.Errorf("invalid image hash %q", g.Hash)
+			}
+			hash, err := hashImage(f.Path, config.ImageHash)
+			if err != nil {
+				return err
+			}
+			matches = bits.OnesCount64(hash^want) <= config.SimilarityThreshold
+		}
+		if size != f.Size {
+			return fmt.Errorf("%s changed size from %s to %s after the decisions file was written", f.Path, formatBytes(f.Size), formatBytes(size))
+		}
+		if !matches {
+			return fmt.Errorf("the content of %s no longer matches the other copies", f.Path)
+		}
+	}
+	return nil
+}
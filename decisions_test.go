@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// decisionsFixture creates two sets of duplicate files and a decisions document for them that
+// keeps the first copy of each set.
+func decisionsFixture(t *testing.T) *decisionsFile {
+	t.Helper()
+	dir := t.TempDir()
+	doc := &decisionsFile{Version: decisionsVersion, Mode: "files", HashAlgo: "sha256", Roots: []string{dir}}
+	for i, content := range []string{"first set", "second set"} {
+		group := decisionGroup{Group: i + 1}
+		for _, name := range []string{"keep", "copy"} {
+			path := filepath.Join(dir, name+string(rune('1'+i)))
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			group.Files = append(group.Files, decisionFile{Path: path, Size: info.Size(), Modified: info.ModTime()})
+		}
+		hash, err := hashFile(group.Files[0].Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		group.Hash, group.Keep = hash, group.Files[0].Path
+		doc.Groups = append(doc.Groups, group)
+	}
+	return doc
+}
+
+// writeDecisionsFile writes a decisions document in the format given by the extension.
+func writeDecisionsFile(t *testing.T, doc *decisionsFile, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	data := []byte(writeDecisionsText(*doc))
+	if strings.HasSuffix(name, ".json") {
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDecisionsTextAndJSONAgree(t *testing.T) {
+	defer func(old Config) { config = old }(config)
+	doc := decisionsFixture(t)
+	text, err := loadDecisions(writeDecisionsFile(t, doc, "dupes.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := loadDecisions(writeDecisionsFile(t, doc, "dupes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, loaded := range []*decisionsFile{text, fromJSON} {
+		if loaded.Mode != doc.Mode || loaded.HashAlgo != doc.HashAlgo || strings.Join(loaded.Roots, ",") != strings.Join(doc.Roots, ",") {
+			t.Errorf("loaded header %s/%s/%v, want %s/%s/%v", loaded.Mode, loaded.HashAlgo, loaded.Roots, doc.Mode, doc.HashAlgo, doc.Roots)
+		}
+		if len(loaded.Groups) != len(doc.Groups) {
+			t.Fatalf("loaded %d groups, want %d", len(loaded.Groups), len(doc.Groups))
+		}
+		for i, g := range loaded.Groups {
+			want := doc.Groups[i]
+			if g.Group != want.Group || g.Hash != want.Hash || g.Keep != want.Keep || len(g.Files) != len(want.Files) {
+				t.Fatalf("group %d = %+v, want %+v", i+1, g, want)
+			}
+			for j, f := range g.Files {
+				if f.Path != want.Files[j].Path || f.Size != want.Files[j].Size || !f.Modified.Equal(want.Files[j].Modified) {
+					t.Errorf("group %d file %d = %+v, want %+v", i+1, j+1, f, want.Files[j])
+				}
+			}
+			if err := validateDecisionGroup(loaded, g); err != nil {
+				t.Errorf("group %d of an unchanged tree: %v", i+1, err)
+			}
+		}
+	}
+}
+
+func TestValidateDecisionGroup(t *testing.T) {
+	defer func(old Config) { config = old }(config)
+
+	t.Run("edited after export", func(t *testing.T) {
+		doc := decisionsFixture(t)
+		g := doc.Groups[0]
+		// Same size and modification time, different content: only the hash can tell.
+		path := g.Files[0].Path
+		if err := os.WriteFile(path, []byte("FIRST SET"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, g.Files[0].Modified, g.Files[0].Modified); err != nil {
+			t.Fatal(err)
+		}
+		if err := validateDecisionGroup(doc, g); err == nil || !strings.Contains(err.Error(), "no longer matches") {
+			t.Errorf("validateDecisionGroup = %v, want a content mismatch", err)
+		}
+	})
+
+	t.Run("path no longer exists", func(t *testing.T) {
+		doc := decisionsFixture(t)
+		g := doc.Groups[0]
+		if err := os.Remove(g.Files[1].Path); err != nil {
+			t.Fatal(err)
+		}
+		if err := validateDecisionGroup(doc, g); err == nil {
+			t.Error("validateDecisionGroup accepted a set with a missing copy")
+		}
+	})
+
+	t.Run("no hash", func(t *testing.T) {
+		doc := decisionsFixture(t)
+		g := doc.Groups[0]
+		g.Hash = ""
+		if err := validateDecisionGroup(doc, g); err == nil {
+			t.Error("validateDecisionGroup accepted a set without a hash")
+		}
+	})
+}
+
+// TestApplyDecisions checks that only unchanged sets with a keep: line are applied.
+func TestApplyDecisions(t *testing.T) {
+	defer func(old Config) { config = old }(config)
+	config.Force = true
+	doc := decisionsFixture(t)
+
+	// The first set has no keep: line, the second one keeps a copy that was edited since.
+	text := strings.Replace(writeDecisionsText(*doc), "keep:\t", "delete:\t", 1)
+	edited := doc.Groups[1].Files[0]
+	if err := os.WriteFile(edited.Path, []byte("SECOND SET"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(edited.Path, edited.Modified, edited.Modified); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "dupes.txt")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadDecisions(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Groups[0].Keep != "" {
+		t.Fatalf("set 1 keeps %s, want no keeper", loaded.Groups[0].Keep)
+	}
+
+	if err := applyDecisions(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range doc.Groups {
+		for _, f := range g.Files {
+			if _, err := os.Stat(f.Path); err != nil {
+				t.Errorf("%s was removed: %v", f.Path, err)
+			}
+		}
+	}
+
+	// Once the file is exported again from the current state, the second set is applied.
+	doc = decisionsFixture(t)
+	path = writeDecisionsFile(t, doc, "dupes.json")
+	if err := applyDecisions(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range doc.Groups {
+		if _, err := os.Stat(g.Keep); err != nil {
+			t.Errorf("the kept copy %s was removed", g.Keep)
+		}
+		if _, err := os.Stat(g.Files[1].Path); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", g.Files[1].Path)
+		}
+	}
+}
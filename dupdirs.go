@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"       // For cancelling the scan on Ctrl+C.
+	"fmt"           // For building the tree digests and error messages.
+	"os"            // For reading directories and symlinks.
+	"path/filepath" // For walking the trees and relating directories to their parents.
+	"sort"          // For hashing directory entries in a stable order.
+	"sync"          // For collecting file hashes from the parallel scan.
+)
+
+// --- Duplicate Directory Detection ---
+// 'find --duplicate-dirs' computes a Merkle-style hash for every directory: the hash of its
+// sorted entries, where each entry contributes its name and either the content hash of a file
+// or the tree hash of a subdirectory. Two directories with the same tree hash hold identical
+// trees, so all but one copy can be removed as a whole.
+
+// dirTree is the computed hash of one directory.
+type dirTree struct {
+	Hash  string
+	Files int   // Number of regular files in the whole tree.
+	Size  int64 // Total size of those files in bytes.
+}
+
+// findDuplicateDirs scans for identical directory trees below the target directories.
+// The target directories themselves are never reported or removed, so two identical
+// extractions given as separate paths are compared by their contents.
+func findDuplicateDirs(ctx context.Context, targetDirs []string, runCtx *runContext, strategy *keepStrategy) error {
+	// Hash every file first, using the same parallel scanner as the duplicate file search.
+	fileHashes := make(map[string]string)
+	var mu sync.Mutex
+	processFile := func(path string, info os.FileInfo) {
+		if runCtx.shouldExclude(path) || !info.Mode().IsRegular() {
+			return
+		}
+		hash, err := hashFile(path)
+		if err != nil {
+			addError(err)
+			return
+		}
+		mu.Lock()
+		fileHashes[path] = hash
+		mu.Unlock()
+	}
+	if err := scanFilesParallel(ctx, targetDirs, getPathGuard().skipDir, processFile); err != nil {
+		return err
+	}
+
+	var dirs []string
+	for _, targetDir := range targetDirs {
+		err := filepath.WalkDir(targetDir, func(path string, d os.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if path != targetDir && (runCtx.shouldExclude(path) || getPathGuard().skipDir(path)) {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Deepest directories first, so every subdirectory is hashed before its parent.
+	sort.SliceStable(dirs, func(i, j int) bool { return pathDepth(dirs[i]) > pathDepth(dirs[j]) })
+	trees := make(map[string]dirTree, len(dirs))
+	for _, dir := range dirs {
+		if tree, ok := hashDirTree(dir, fileHashes, trees, runCtx); ok {
+			trees[dir] = tree
+		}
+	}
+
+	byHash := make(map[string][]string)
+	for dir, tree := range trees {
+		// Trees without any files are left to the 'empty' command.
+		if tree.Files > 0 && !contains(targetDirs, dir) {
+			byHash[tree.Hash] = append(byHash[tree.Hash], dir)
+		}
+	}
+
+	var groups []*duplicateGroup
+	for hash, members := range byHash {
+		if len(members) < 2 || isImpliedByParents(members, trees, byHash) {
+			continue
+		}
+		sort.Strings(members)
+		logVerbose("Found duplicate directory set for hash %s: %v", hash, describeRoots(targetDirs, members))
+		groups = append(groups, &duplicateGroup{Hash: hash, Size: trees[members[0]].Size, Paths: members})
+	}
+	// Most wasted space first, which also decides nested sets after the trees containing them.
+	sortDuplicateGroups(groups)
+
+	logInfo("\n🗂️  Found %d sets of duplicate directories.", len(groups))
+	var pathsToDelete, keptPaths []string
+	for i, group := range groups {
+		// Skip copies that are already removed as part of a larger tree, and never remove a
+		// tree that contains a copy kept for an earlier set.
+		var candidates []string
+		for _, dir := range group.Paths {
+			if !isWithinAny(pathsToDelete, dir) {
+				candidates = append(candidates, dir)
+			}
+		}
+		keep, toDelete, err := processDuplicateSet(candidates, i+1, group.Size, strategy)
+		if err != nil {
+			addError(err)
+			continue
+		}
+		if keep == "" {
+			// A skipped set keeps every copy.
+			keptPaths = append(keptPaths, candidates...)
+		} else {
+			keptPaths = append(keptPaths, keep)
+		}
+		var safeToDelete []string
+		for _, dir := range toDelete {
+			if containsAny(dir, keptPaths) {
+				logVerbose("  -> Not removing %s: it contains a kept copy", dir)
+				continue
+			}
+			safeToDelete = append(safeToDelete, dir)
+		}
+		group.decide(keep, safeToDelete, func(dir string) int64 { return trees[dir].Size })
+		pathsToDelete = append(pathsToDelete, safeToDelete...)
+	}
+	return finishDuplicateGroups("dirs", "duplicate directories", groups, targetDirs, func(dir string) int64 { return trees[dir].Size })
+}
+
+// hashDirTree computes the tree hash of a directory from the hashes of its entries.
+// It returns false if any entry is excluded or could not be hashed, since removing such a
+// directory would also remove content that was never compared.
+func hashDirTree(dir string, fileHashes map[string]string, trees map[string]dirTree, runCtx *runContext) (dirTree, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		addError(fmt.Errorf("could not read directory %s: %w", dir, err))
+		return dirTree{}, false
+	}
+
+	h := newHasher()
+	var tree dirTree
+	for _, entry := range entries { // os.ReadDir returns the entries sorted by name.
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir():
+			sub, ok := trees[path]
+			if !ok {
+				return dirTree{}, false
+			}
+			fmt.Fprintf(h, "d %q %s\n", entry.Name(), sub.Hash)
+			tree.Files += sub.Files
+			tree.Size += sub.Size
+		case entry.Type()&os.ModeSymlink != 0:
+			if runCtx.shouldExclude(path) {
+				return dirTree{}, false
+			}
+			target, err := os.Readlink(path)
+			if err != nil {
+				addError(fmt.Errorf("could not read symlink %s: %w", path, err))
+				return dirTree{}, false
+			}
+			fmt.Fprintf(h, "l %q %q\n", entry.Name(), target)
+		case entry.Type().IsRegular():
+			hash, ok := fileHashes[path]
+			if !ok {
+				return dirTree{}, false
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return dirTree{}, false
+			}
+			fmt.Fprintf(h, "f %q %s\n", entry.Name(), hash)
+			tree.Files++
+			tree.Size += info.Size()
+		default:
+			// Sockets, devices and pipes cannot be compared by content.
+			return dirTree{}, false
+		}
+	}
+	tree.Hash = fmt.Sprintf("%x", h.Sum(nil))
+	return tree, true
+}
+
+// rehashDirTree hashes a directory tree again from scratch, to check that a copy recorded in a
+// decisions file still has the content it had when the file was written.
+func rehashDirTree(dir string) (dirTree, error) {
+	fileHashes := make(map[string]string)
+	var dirs []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", path, err)
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		} else if d.Type().IsRegular() {
+			hash, err := hashFile(path)
+			if err != nil {
+				return err
+			}
+			fileHashes[path] = hash
+		}
+		return nil
+	})
+	if err != nil {
+		return dirTree{}, err
+	}
+
+	sort.SliceStable(dirs, func(i, j int) bool { return pathDepth(dirs[i]) > pathDepth(dirs[j]) })
+	trees := make(map[string]dirTree, len(dirs))
+	for _, d := range dirs {
+		tree, ok := hashDirTree(d, fileHashes, trees, &runContext{})
+		if !ok {
+			return dirTree{}, fmt.Errorf("%s contains entries that cannot be compared", d)
+		}
+		trees[d] = tree
+	}
+	return trees[dir], nil
+}
+
+// isImpliedByParents reports whether a set of identical directories is only a consequence of
+// their parents being identical too, e.g. 'a/src' and 'a (copy)/src'. Such sets are covered
+// by the parents' set and are not reported separately.
+func isImpliedByParents(members []string, trees map[string]dirTree, byHash map[string][]string) bool {
+	parents := make(map[string]struct{}, len(members))
+	parentHash := ""
+	for _, dir := range members {
+		parent := filepath.Dir(dir)
+		tree, ok := trees[parent]
+		if !ok || (parentHash != "" && tree.Hash != parentHash) {
+			return false
+		}
+		parentHash = tree.Hash
+		parents[parent] = struct{}{}
+	}
+	return len(parents) == len(members) && len(byHash[parentHash]) > 1
+}
+
+// isWithinAny reports whether path is one of dirs or lies below one of them.
+func isWithinAny(dirs []string, path string) bool {
+	for _, dir := range dirs {
+		if isWithin(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny reports whether any of paths is dir itself or lies below it.
+func containsAny(dir string, paths []string) bool {
+	for _, p := range paths {
+		if isWithin(dir, p) {
+			return true
+		}
+	}
+	return false
+}
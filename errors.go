@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/csv"  // For writing --errors-out and CSV error tables.
+	"encoding/json" // For writing --errors-out and JSON error documents.
+	"errors"        // For unwrapping the underlying OS errors.
+	"fmt"           // For formatting error records.
+	"io"            // For writing error records to any writer.
+	"io/fs"         // For recognising permission and not-found errors.
+	"os"            // For creating the --errors-out file.
+	"path/filepath" // For choosing the --errors-out format by extension.
+	"runtime"       // For the Windows sharing violation codes.
+	"strings"       // For matching file extensions.
+	"syscall"       // For reading errno values.
+)
+
+// --- Structured Errors ---
+// Every non-fatal error is kept as a record with the path and operation it happened on, the
+// errno, and a category (permission, not-found, busy, io or other), so that failed paths can be
+// filtered, retried or investigated instead of read back from log lines.
+
+// Error categories.
+const (
+	errPermission = "permission"
+	errNotFound   = "not-found"
+	errBusy       = "busy"
+	errIO         = "io"
+	errOther      = "other"
+)
+
+// errorRecord is a non-fatal error collected during a run.
+type errorRecord struct {
+	Path     string `json:"path,omitempty"`
+	Op       string `json:"op,omitempty"`
+	Category string `json:"category"`
+	Errno    string `json:"errno,omitempty"`
+	Message  string `json:"message"`
+}
+
+// errorHeaders are the CSV columns of an error record.
+var errorHeaders = []string{"path", "op", "category", "errno", "message"}
+
+func (r errorRecord) csvRecord() []string {
+	return []string{r.Path, r.Op, r.Category, r.Errno, r.Message}
+}
+
+// taggedError attaches an operation and path to an error that doesn't carry them itself,
+// or whose own path is less useful than the item being processed.
+type taggedError struct {
+	op   string
+	path string
+	err  error
+}
+
+func (e *taggedError) Error() string { return e.err.Error() }
+func (e *taggedError) Unwrap() error { return e.err }
+
+// tagError records the operation and path an error happened on.
+func tagError(op, path string, err error) error {
+	return &taggedError{op: op, path: path, err: err}
+}
+
+// errnoNames lists the errno values worth naming with their symbolic names. It is a list rather
+// than a map because some platforms share values between names (e.g. EEXIST and ENOTEMPTY on AIX).
+var errnoNames = []struct {
+	errno syscall.Errno
+	name  string
+}{
+	{syscall.EACCES, "EACCES"},
+	{syscall.EPERM, "EPERM"},
+	{syscall.ENOENT, "ENOENT"},
+	{syscall.ENOTDIR, "ENOTDIR"},
+	{syscall.EISDIR, "EISDIR"},
+	{syscall.EEXIST, "EEXIST"},
+	{syscall.ENOTEMPTY, "ENOTEMPTY"},
+	{syscall.EBUSY, "EBUSY"},
+	{syscall.EIO, "EIO"},
+	{syscall.ENOSPC, "ENOSPC"},
+	{syscall.EROFS, "EROFS"},
+	{syscall.ELOOP, "ELOOP"},
+	{syscall.ENAMETOOLONG, "ENAMETOOLONG"},
+	{syscall.EXDEV, "EXDEV"},
+}
+
+// newErrorRecord classifies an error and extracts its path, operation and errno.
+func newErrorRecord(err error) errorRecord {
+	record := errorRecord{Message: err.Error(), Category: errOther}
+
+	var tagged *taggedError
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	var syscallErr *os.SyscallError
+	switch {
+	case errors.As(err, &tagged):
+		record.Op, record.Path = tagged.op, tagged.path
+	case errors.As(err, &pathErr):
+		record.Op, record.Path = pathErr.Op, pathErr.Path
+	case errors.As(err, &linkErr):
+		record.Op, record.Path = linkErr.Op, linkErr.Old
+	case errors.As(err, &syscallErr):
+		record.Op = syscallErr.Syscall
+	}
+
+	var errno syscall.Errno
+	hasErrno := errors.As(err, &errno)
+	if hasErrno {
+		record.Errno = fmt.Sprintf("errno %d", uintptr(errno))
+		for _, e := range errnoNames {
+			if e.errno == errno {
+				record.Errno = e.name
+				break
+			}
+		}
+	}
+
+	switch {
+	case errors.Is(err, fs.ErrPermission) || (hasErrno && errno == syscall.EROFS):
+		record.Category = errPermission
+	case errors.Is(err, fs.ErrNotExist):
+		record.Category = errNotFound
+	case hasErrno && isBusyErrno(errno):
+		record.Category = errBusy
+	case hasErrno:
+		record.Category = errIO
+	}
+	return record
+}
+
+// isBusyErrno reports whether an errno means that the file is in use by another process.
+func isBusyErrno(errno syscall.Errno) bool {
+	if errno == syscall.EBUSY {
+		return true
+	}
+	// ERROR_SHARING_VIOLATION and ERROR_LOCK_VIOLATION: the file is open in another program.
+	return runtime.GOOS == "windows" && (errno == 32 || errno == 33)
+}
+
+// logFields returns the details of a record as fields for the structured log.
+func (r errorRecord) logFields() []any {
+	fields := []any{"category", r.Category}
+	if r.Op != "" {
+		fields = append(fields, "op", r.Op)
+	}
+	if r.Errno != "" {
+		fields = append(fields, "errno", r.Errno)
+	}
+	if r.Path != "" {
+		fields = append(fields, "path", r.Path)
+	}
+	return fields
+}
+
+// collectedErrors returns a copy of the errors collected so far.
+func collectedErrors() []errorRecord {
+	errorMutex.Lock()
+	defer errorMutex.Unlock()
+	return append([]errorRecord(nil), errorList...)
+}
+
+// writeErrorsCSV writes error records as a CSV table.
+func writeErrorsCSV(records []errorRecord, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(errorHeaders); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := csvWriter.Write(r.csvRecord()); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeErrorsOut writes the collected errors to the --errors-out file: a JSON array for '.json',
+// CSV for '.csv', and otherwise one tab-separated 'category, op, path, message' line per error.
+// The file is always written, so an empty file means the run had no errors.
+func writeErrorsOut(path string) error {
+	records := collectedErrors()
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create errors file: %w", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if records == nil {
+			records = []errorRecord{}
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(records)
+	case ".csv":
+		err = writeErrorsCSV(records, file)
+	default:
+		for _, r := range records {
+			if _, err = fmt.Fprintf(file, "%s\t%s\t%s\t%s\n", r.Category, r.Op, r.Path, r.Message); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not write errors file: %w", err)
+	}
+	return file.Close()
+}
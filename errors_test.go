@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestNewErrorRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		category string
+		op, path string
+		errno    string
+	}{
+		{"permission denied", &fs.PathError{Op: "remove", Path: "/srv/a", Err: syscall.EACCES}, errPermission, "remove", "/srv/a", "EACCES"},
+		{"operation not permitted", &fs.PathError{Op: "unlinkat", Path: "/srv/a", Err: syscall.EPERM}, errPermission, "unlinkat", "/srv/a", "EPERM"},
+		{"read-only filesystem", &fs.PathError{Op: "remove", Path: "/mnt/ro/a", Err: syscall.EROFS}, errPermission, "remove", "/mnt/ro/a", "EROFS"},
+		{"sentinel permission", fmt.Errorf("trash: %w", fs.ErrPermission), errPermission, "", "", ""},
+		{"not found", &fs.PathError{Op: "lstat", Path: "/srv/gone", Err: syscall.ENOENT}, errNotFound, "lstat", "/srv/gone", "ENOENT"},
+		{"sentinel not found", fmt.Errorf("open: %w", fs.ErrNotExist), errNotFound, "", "", ""},
+		{"busy", &fs.PathError{Op: "remove", Path: "/mnt/usb", Err: syscall.EBUSY}, errBusy, "remove", "/mnt/usb", "EBUSY"},
+		{"I/O error", &fs.PathError{Op: "read", Path: "/srv/bad", Err: syscall.EIO}, errIO, "read", "/srv/bad", "EIO"},
+		{"disk full", &os.LinkError{Op: "rename", Old: "/srv/a", New: "/trash/a", Err: syscall.ENOSPC}, errIO, "rename", "/srv/a", "ENOSPC"},
+		{"syscall error", os.NewSyscallError("getdents", syscall.EIO), errIO, "getdents", "", "EIO"},
+		{"tagged", tagError("hash", "/srv/b", &fs.PathError{Op: "read", Path: "/srv/b", Err: syscall.EIO}), errIO, "hash", "/srv/b", "EIO"},
+		{"other", errors.New("the set changed since it was exported"), errOther, "", "", ""},
+		{"tagged other", tagError("compare", "/srv/c", errors.New("sizes differ")), errOther, "compare", "/srv/c", ""},
+	}
+	for _, tt := range tests {
+		r := newErrorRecord(tt.err)
+		if r.Category != tt.category || r.Op != tt.op || r.Path != tt.path || r.Errno != tt.errno {
+			t.Errorf("%s: got category %q, op %q, path %q, errno %q; want %q, %q, %q, %q",
+				tt.name, r.Category, r.Op, r.Path, r.Errno, tt.category, tt.op, tt.path, tt.errno)
+		}
+		if r.Message != tt.err.Error() {
+			t.Errorf("%s: message = %q, want %q", tt.name, r.Message, tt.err.Error())
+		}
+	}
+}
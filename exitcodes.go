@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"     // For recognising cancelled runs.
+	"errors"      // For unwrapping cancellation errors.
+	"sync/atomic" // For recording found items from any goroutine.
+)
+
+// --- Exit Codes ---
+// The exit code tells scripts and monitoring what a run did:
+//
+//	0   Success: nothing to do, or everything found was handled.
+//	1   Fatal error: invalid flags or config, a failed scan, or an aborted deletion.
+//	2   Partial failure: the run completed, but some files could not be read or deleted.
+//	3   Items were found but nothing was changed (dry run, decisions export or declined prompt).
+//	130 Cancelled with Ctrl+C or SIGTERM.
+const (
+	exitOK        = 0
+	exitFatal     = 1
+	exitPartial   = 2
+	exitFound     = 3
+	exitCancelled = 130
+)
+
+// itemsLeftInPlace is set when a run found items to delete but did not change anything.
+var itemsLeftInPlace atomic.Bool
+
+// markItemsFound records that items were found and left in place.
+func markItemsFound() {
+	itemsLeftInPlace.Store(true)
+}
+
+// exitCode determines the exit code from the error returned by the command and the run's state.
+func exitCode(ctx context.Context, err error) int {
+	switch {
+	case errors.Is(err, context.Canceled) || ctx.Err() != nil:
+		return exitCancelled
+	case err != nil:
+		return exitFatal
+	}
+	errorMutex.Lock()
+	hadErrors := len(errorList) > 0
+	errorMutex.Unlock()
+	switch {
+	case hadErrors:
+		return exitPartial
+	case itemsLeftInPlace.Load():
+		return exitFound
+	}
+	return exitOK
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"           // For error messages.
+	"path/filepath" // For matching glob patterns against file names.
+	"runtime"       // For choosing the junk preset of the current platform.
+	"strings"       // For detecting glob patterns and case-insensitive matching.
+)
+
+// --- Ignore Lists ---
+// Files listed with --ignore-files don't keep a folder from being empty. Entries are either exact
+// names (desktop.ini) or glob patterns (*.tmp, ._*), and --junk-preset adds the files that
+// operating systems leave behind on their own.
+
+// junkPresets lists the files each operating system creates without the user asking for them.
+var junkPresets = map[string][]string{
+	"macos":   {".DS_Store", "._*", ".localized", "Icon\r", ".apdisk"},
+	"windows": {"Thumbs.db", "ehthumbs.db", "ehthumbs_vista.db", "desktop.ini", "Desktop.ini"},
+	"linux":   {".directory"}, // KDE folder settings.
+}
+
+// junkPresetNames lists the valid values of --junk-preset.
+var junkPresetNames = []string{"auto", "all", "macos", "windows", "linux"}
+
+// junkPreset returns the file patterns of a preset. "auto" picks the current platform and
+// "all" combines every platform, which is useful for drives shared between systems.
+func junkPreset(name string) ([]string, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "auto":
+		switch runtime.GOOS {
+		case "darwin", "ios":
+			return junkPresets["macos"], nil
+		case "windows":
+			return junkPresets["windows"], nil
+		}
+		return junkPresets["linux"], nil
+	case "all":
+		var all []string
+		for _, preset := range []string{"macos", "windows", "linux"} {
+			all = append(all, junkPresets[preset]...)
+		}
+		return all, nil
+	}
+	if patterns, ok := junkPresets[name]; ok {
+		return patterns, nil
+	}
+	return nil, fmt.Errorf("invalid value for --junk-preset: %q. Allowed values are: [%s]", name, strings.Join(junkPresetNames, ", "))
+}
+
+// ignoreMatcher decides whether a file name is on the ignore list.
+type ignoreMatcher struct {
+	names      map[string]struct{}
+	patterns   []string
+	ignoreCase bool
+}
+
+// newIgnoreMatcher builds a matcher from exact names and glob patterns.
+// Names are compared case-insensitively on platforms whose filesystems usually are.
+func newIgnoreMatcher(entries []string) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{names: make(map[string]struct{}), ignoreCase: runtime.GOOS == "windows" || runtime.GOOS == "darwin"}
+	for _, entry := range entries {
+		if m.ignoreCase {
+			entry = strings.ToLower(entry)
+		}
+		if !strings.ContainsAny(entry, "*?[") {
+			m.names[entry] = struct{}{}
+			continue
+		}
+		if _, err := filepath.Match(entry, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q in --ignore-files: %w", entry, err)
+		}
+		m.patterns = append(m.patterns, entry)
+	}
+	return m, nil
+}
+
+// matches reports whether a file name is ignored.
+func (m *ignoreMatcher) matches(name string) bool {
+	if m.ignoreCase {
+		name = strings.ToLower(name)
+	}
+	if _, ok := m.names[name]; ok {
+		return true
+	}
+	for _, pattern := range m.patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
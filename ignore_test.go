@@ -0,0 +1,56 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	m, err := newIgnoreMatcher([]string{".gitkeep", "*.keep", "Thumbs.db", "~$*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caseInsensitive := runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{".gitkeep", true},
+		{"placeholder.keep", true},
+		{".keep", true},
+		{"Thumbs.db", true},
+		{"~$report.docx", true},
+		{"gitkeep", false},
+		{".gitkeep.bak", false},
+		{"keep.txt", false},
+		{"notes.keeper", false},
+		{"data.csv", false},
+		{"THUMBS.DB", caseInsensitive},
+		{"README.KEEP", caseInsensitive},
+	}
+	for _, tt := range tests {
+		if got := m.matches(tt.name); got != tt.want {
+			t.Errorf("matches(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := newIgnoreMatcher([]string{"[.keep"}); err == nil {
+		t.Error("newIgnoreMatcher accepted an invalid glob pattern")
+	}
+}
+
+func TestJunkPreset(t *testing.T) {
+	all, err := junkPreset("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"macos", "windows", "linux"} {
+		patterns, err := junkPreset(name)
+		if err != nil || len(patterns) == 0 || len(patterns) > len(all) {
+			t.Errorf("junkPreset(%q) = %v, %v", name, patterns, err)
+		}
+	}
+	if _, err := junkPreset("amiga"); err == nil {
+		t.Error("junkPreset accepted an unknown preset")
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"       // For cancelling the scan on Ctrl+C.
+	"fmt"           // For error messages.
+	"image"         // For decoding images into pixels and reading their dimensions.
+	_ "image/gif"   // Registers the GIF decoder.
+	_ "image/jpeg"  // Registers the JPEG decoder.
+	_ "image/png"   // Registers the PNG decoder.
+	"math"          // For the cosine transform of the perceptual hash.
+	"math/bits"     // For counting differing bits between two hashes.
+	"os"            // For opening image files.
+	"path/filepath" // For checking file extensions.
+	"sort"          // For the median of the perceptual hash and stable set order.
+	"strings"       // For case-insensitive extension checks.
+	"sync"          // For collecting hashes from the parallel scan.
+)
+
+// --- Similar Image Detection ---
+// 'find --similar-images' compares pictures by what they look like rather than by their bytes,
+// so resized or re-encoded copies of the same photo are found as well. Every image is reduced
+// to a 64-bit perceptual hash; images whose hashes differ in at most --similarity-threshold
+// bits are grouped together and handled like duplicate files.
+
+// imageExtensions lists the formats that can be decoded with the standard library.
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".gif"}
+
+// imageHash is a 64-bit perceptual hash of an image.
+type imageHash struct {
+	Path string
+	Hash uint64
+}
+
+// findSimilarImages scans for images that look alike and applies the keep strategy to each group.
+func findSimilarImages(ctx context.Context, targetDirs []string, runCtx *runContext, strategy *keepStrategy) error {
+	var hashes []imageHash
+	var mu sync.Mutex
+
+	processFile := func(path string, info os.FileInfo) {
+		if runCtx.shouldExclude(path) || info.Size() == 0 || !info.Mode().IsRegular() {
+			return
+		}
+		if !contains(imageExtensions, strings.ToLower(filepath.Ext(path))) {
+			return
+		}
+		hash, err := hashImage(path, config.ImageHash)
+		if err != nil {
+			addError(err)
+			return
+		}
+		logVerbose("Hashed image %s (%s): %016x", path, config.ImageHash, hash)
+		mu.Lock()
+		hashes = append(hashes, imageHash{Path: path, Hash: hash})
+		mu.Unlock()
+	}
+
+	if err := scanFilesParallel(ctx, targetDirs, getPathGuard().skipDir, processFile); err != nil {
+		return err
+	}
+
+	groups := clusterImageHashes(hashes, config.SimilarityThreshold)
+	for _, group := range groups {
+		logVerbose("Found similar image set: %v", describeRoots(targetDirs, group.Paths))
+	}
+	sortDuplicateGroups(groups)
+	logInfo("\n🖼️  Found %d sets of similar images.", len(groups))
+
+	for i, group := range groups {
+		keep, toDelete, err := processDuplicateSet(group.Paths, i+1, group.Size, strategy)
+		if err != nil {
+			addError(err)
+			continue
+		}
+		group.decide(keep, toDelete, getFileSize)
+	}
+	return finishDuplicateGroups("images", "similar images", groups, targetDirs, getFileSize)
+}
+
+// clusterImageHashes groups images whose hashes are within the given Hamming distance of
+// every other image in the group (complete linkage). Similarity is not transitive: if A and C
+// are both close to B but not to each other, they must not end up in one group where the keep
+// strategy could delete C in favour of A. Each group carries the hash of its first image.
+func clusterImageHashes(hashes []imageHash, threshold int) []*duplicateGroup {
+	// Sort first so that the groups and their order don't depend on the scan order.
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i].Path < hashes[j].Path })
+
+	grouped := make([]bool, len(hashes))
+	var sets []*duplicateGroup
+	for i, h := range hashes {
+		if grouped[i] {
+			continue
+		}
+		members := []uint64{h.Hash}
+		group := &duplicateGroup{Hash: fmt.Sprintf("%016x", h.Hash), Size: getFileSize(h.Path), Paths: []string{h.Path}}
+		for j := i + 1; j < len(hashes); j++ {
+			if grouped[j] || !withinDistance(hashes[j].Hash, members, threshold) {
+				continue
+			}
+			grouped[j] = true
+			members = append(members, hashes[j].Hash)
+			group.Paths = append(group.Paths, hashes[j].Path)
+		}
+		if len(group.Paths) > 1 {
+			sets = append(sets, group)
+		}
+	}
+	return sets
+}
+
+// withinDistance reports whether hash differs in at most threshold bits from each of the others.
+func withinDistance(hash uint64, others []uint64, threshold int) bool {
+	for _, other := range others {
+		if bits.OnesCount64(hash^other) > threshold {
+			return false
+		}
+	}
+	return true
+}
+
+// hashImage decodes an image and computes its perceptual hash with the given algorithm.
+func hashImage(path, algorithm string) (uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("could not open image %s: %w", path, err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return 0, fmt.Errorf("could not decode image %s: %w", path, err)
+	}
+	if algorithm == "phash" {
+		return pHash(img), nil
+	}
+	return dHash(img), nil
+}
+
+// dHash is the difference hash: the image is shrunk to 9x8 gray pixels and each bit records
+// whether a pixel is brighter than its right neighbour. It is fast and robust to scaling.
+func dHash(img image.Image) uint64 {
+	pixels := grayscale(img, 9, 8)
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if pixels[y*9+x] > pixels[y*9+x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// pHash is the perceptual hash: the image is shrunk to 32x32 gray pixels and transformed with
+// a discrete cosine transform. Each bit records whether one of the 8x8 lowest frequencies is
+// above their median. It is slower than dHash but tolerates re-encoding and small edits better.
+func pHash(img image.Image) uint64 {
+	const size, low = 32, 8
+	pixels := grayscale(img, size, size)
+
+	// cosines[u][x] = cos((2x+1)uπ / 2N), shared by the row and column passes.
+	var cosines [low][size]float64
+	for u := 0; u < low; u++ {
+		for x := 0; x < size; x++ {
+			cosines[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * size))
+		}
+	}
+	var rows [size][low]float64
+	for y := 0; y < size; y++ {
+		for u := 0; u < low; u++ {
+			for x := 0; x < size; x++ {
+				rows[y][u] += pixels[y*size+x] * cosines[u][x]
+			}
+		}
+	}
+	coefficients := make([]float64, 0, low*low)
+	for v := 0; v < low; v++ {
+		for u := 0; u < low; u++ {
+			var sum float64
+			for y := 0; y < size; y++ {
+				sum += rows[y][u] * cosines[v][y]
+			}
+			coefficients = append(coefficients, sum)
+		}
+	}
+
+	// The first coefficient is the average brightness and would dominate the median.
+	sorted := append([]float64(nil), coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	var hash uint64
+	for _, c := range coefficients {
+		hash <<= 1
+		if c > median {
+			hash |= 1
+		}
+	}
+	return hash
+}
+
+// grayscale shrinks an image to width x height luminance values by averaging the source
+// pixels that fall into each target cell.
+func grayscale(img image.Image, width, height int) []float64 {
+	bounds := img.Bounds()
+	pixels := make([]float64, width*height)
+	for ty := 0; ty < height; ty++ {
+		y0 := bounds.Min.Y + ty*bounds.Dy()/height
+		y1 := max(bounds.Min.Y+(ty+1)*bounds.Dy()/height, y0+1)
+		for tx := 0; tx < width; tx++ {
+			x0 := bounds.Min.X + tx*bounds.Dx()/width
+			x1 := max(bounds.Min.X+(tx+1)*bounds.Dx()/width, x0+1)
+			var sum float64
+			var count int
+			for y := y0; y < y1 && y < bounds.Max.Y; y++ {
+				for x := x0; x < x1 && x < bounds.Max.X; x++ {
+					r, g, b, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+					count++
+				}
+			}
+			if count > 0 {
+				pixels[ty*width+tx] = sum / float64(count)
+			}
+		}
+	}
+	return pixels
+}
+
+// imagePixels returns the number of pixels of an image, or 0 if it is not a decodable image.
+// Only the header is read, so this is cheap even for large photos.
+func imagePixels(path string) int64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0
+	}
+	return int64(cfg.Width) * int64(cfg.Height)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+	"testing"
+)
+
+// gradient draws a horizontal brightness gradient, falling from left to right unless rising is set.
+func gradient(width, height int, rising bool) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(255 - x*255/(width-1))
+			if rising {
+				v = 255 - v
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+// checkerboard draws squares of the given size in black and white.
+func checkerboard(width, height, square int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (x/square+y/square)%2 == 0 {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestDHash(t *testing.T) {
+	if got := dHash(gradient(90, 80, false)); got != ^uint64(0) {
+		t.Errorf("dHash(falling gradient) = %016x, want all bits set", got)
+	}
+	if got := dHash(gradient(90, 80, true)); got != 0 {
+		t.Errorf("dHash(rising gradient) = %016x, want no bits set", got)
+	}
+	if a, b := dHash(gradient(90, 80, false)), dHash(gradient(450, 400, false)); a != b {
+		t.Errorf("dHash changed with the image size: %016x vs %016x", a, b)
+	}
+}
+
+func TestPHash(t *testing.T) {
+	small, large := pHash(checkerboard(64, 64, 8)), pHash(checkerboard(256, 256, 32))
+	if d := bits.OnesCount64(small ^ large); d > 4 {
+		t.Errorf("pHash of a scaled copy differs in %d bits, want at most 4", d)
+	}
+	if d := bits.OnesCount64(small ^ pHash(gradient(64, 64, false))); d <= 10 {
+		t.Errorf("pHash of different pictures differs in only %d bits", d)
+	}
+}
+
+// TestClusterImageHashesNotTransitive checks that images only share a group if all of them are close.
+func TestClusterImageHashesNotTransitive(t *testing.T) {
+	hashes := []imageHash{
+		{Path: "c.png", Hash: 0b111111},
+		{Path: "a.png", Hash: 0},
+		{Path: "b.png", Hash: 0b111},
+		{Path: "d.png", Hash: 0b111110},
+	}
+	groups := clusterImageHashes(hashes, 3)
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+	for i, want := range [][]string{{"a.png", "b.png"}, {"c.png", "d.png"}} {
+		if got := groups[i].Paths; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("group %d = %v, want %v", i+1, got, want)
+		}
+	}
+	if groups[0].Hash != "0000000000000000" {
+		t.Errorf("group hash = %s, want the hash of its first image", groups[0].Hash)
+	}
+}
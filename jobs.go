@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"         // For replaying a job's log and scanning its error output line by line.
+	"bytes"         // For capturing a job's stderr in memory.
+	"context"       // For cancelling running jobs on Ctrl+C.
+	"encoding/json" // For reading the structured log of a job.
+	"errors"        // For reading a job's exit code.
+	"fmt"           // For formatted messages and flag values.
+	"log/slog"      // For replaying a job's log records with their level and fields.
+	"os"            // For locating our own executable and creating temporary log files.
+	"os/exec"       // For running each job as a separate invocation of this program.
+	"regexp"        // For stripping terminal color codes from captured error lines.
+	"sort"          // For passing flags and log fields in a stable order.
+	"strings"       // For building arguments and cleaning captured lines.
+	"sync"          // For running jobs in parallel.
+	"time"          // For measuring job durations.
+
+	"gopkg.in/yaml.v3" // For reading the jobs file.
+)
+
+// --- Declarative Job Runner ---
+// 'cleanup run jobs.yaml' executes an ordered list of jobs. Each job runs as a separate
+// invocation of this program so that its configuration, logging and errors stay isolated,
+// which also makes it safe to run jobs in parallel.
+
+// JobFile is the top-level structure of a jobs file, e.g.:
+//
+//	parallel: 2
+//	jobs:
+//	  - name: ci-cache
+//	    command: find
+//	    paths: [/var/cache/ci]
+//	    flags: {older-than: 30d}
+//	    action: trash
+type JobFile struct {
+	Parallel int   `yaml:"parallel"` // Maximum number of jobs running at once; 0 or 1 runs them sequentially.
+	Jobs     []Job `yaml:"jobs"`
+}
+
+// Job is a single step of a jobs file.
+type Job struct {
+	Name    string                 `yaml:"name"`    // Label used in the output; defaults to "job-N".
+	Command string                 `yaml:"command"` // One of: empty, find, large, report.
+	Paths   []string               `yaml:"paths"`   // Target paths; the current directory if empty.
+	Profile string                 `yaml:"profile"` // Optional profile from the config file to apply.
+	Flags   map[string]interface{} `yaml:"flags"`   // Command-line flags by name, e.g. {older-than: 30d, recursive: true}.
+	Action  string                 `yaml:"action"`  // For empty/find: dry-run (default), delete or trash.
+}
+
+// jobResult is the outcome of one job.
+type jobResult struct {
+	Job      Job
+	Duration time.Duration
+	Errors   []string
+	Failed   bool
+	Found    bool // The job found items but left them in place (exit code 3), e.g. in a dry run.
+	Log      string
+}
+
+// jobStopTimeout is how long a cancelled job may take to stop before it is killed.
+const jobStopTimeout = 10 * time.Second
+
+// ansiEscape matches terminal color codes, which are stripped from captured error lines.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// loadJobFile reads and validates a jobs file.
+func loadJobFile(path string) (*JobFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read jobs file: %w", err)
+	}
+	var jobFile JobFile
+	if err := yaml.Unmarshal(data, &jobFile); err != nil {
+		return nil, fmt.Errorf("invalid jobs file %s: %w", path, err)
+	}
+	if len(jobFile.Jobs) == 0 {
+		return nil, fmt.Errorf("jobs file %s does not define any jobs", path)
+	}
+
+	for i := range jobFile.Jobs {
+		job := &jobFile.Jobs[i]
+		if job.Name == "" {
+			job.Name = fmt.Sprintf("job-%d", i+1)
+		}
+		if !contains([]string{"empty", "find", "large", "report"}, job.Command) {
+			return nil, fmt.Errorf("job %q: invalid command %q. Allowed values are: [empty, find, large, report]", job.Name, job.Command)
+		}
+		isReadOnly := job.Command == "large" || job.Command == "report"
+		if isReadOnly && job.Action != "" {
+			return nil, fmt.Errorf("job %q: the %s command is read-only and does not take an action", job.Name, job.Command)
+		}
+		if !isReadOnly {
+			if job.Action == "" {
+				job.Action = "dry-run"
+			}
+			if !contains([]string{"dry-run", "delete", "trash"}, job.Action) {
+				return nil, fmt.Errorf("job %q: invalid action %q. Allowed values are: [dry-run, delete, trash]", job.Name, job.Action)
+			}
+		}
+	}
+	return &jobFile, nil
+}
+
+// jobArgs builds the command line for a job.
+// Jobs never prompt: 'delete' and 'trash' imply --force, everything else is a dry run, and
+// without a terminal '--keep prompt' falls back to 'first'.
+func jobArgs(job Job) []string {
+	args := []string{job.Command}
+	for _, path := range job.Paths {
+		args = append(args, expandHome(path))
+	}
+
+	var names []string
+	for name := range job.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var value string
+		switch v := job.Flags[name].(type) {
+		case []interface{}:
+			var items []string
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			value = strings.Join(items, ",")
+		default:
+			value = fmt.Sprint(v)
+		}
+		args = append(args, fmt.Sprintf("--%s=%s", strings.TrimLeft(name, "-"), value))
+	}
+
+	switch job.Action {
+	case "dry-run":
+		args = append(args, "--dry-run")
+	case "delete":
+		args = append(args, "--force")
+	case "trash":
+		args = append(args, "--trash", "--force")
+	}
+	if job.Profile != "" {
+		args = append(args, "--profile", job.Profile)
+	}
+	if config.ConfigFile != "" {
+		args = append(args, "--config", config.ConfigFile)
+	}
+	if config.Verbose {
+		args = append(args, "--verbose")
+	}
+	if config.LogLevel != "" {
+		args = append(args, "--log-level", config.LogLevel)
+	}
+	if config.IKnowWhatImDoing {
+		args = append(args, "--i-know-what-im-doing")
+	}
+	return args
+}
+
+// replayJobLog logs a line of a job's log under the job's name. The child writes JSON records,
+// whose level and fields are kept; other lines, such as results written with --quiet, are logged
+// as they are. Errors are skipped here because they are collected from the child's stderr.
+func replayJobLog(jobName, line string) {
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &record); err != nil || record["msg"] == nil {
+		logInfo("  [%s] %s", jobName, line)
+		return
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(fmt.Sprint(record["level"]))); err != nil {
+		level = slog.LevelInfo
+	}
+	if level >= slog.LevelError {
+		return
+	}
+	var keys []string
+	for key := range record {
+		if key != slog.TimeKey && key != slog.LevelKey && key != slog.MessageKey {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	fields := []any{"job", jobName}
+	for _, key := range keys {
+		name := key
+		if key == "run_id" {
+			name = "job_run_id" // The record already carries the run ID of this run.
+		}
+		fields = append(fields, name, record[key])
+	}
+	logEvent(level, fmt.Sprintf("  [%s] %v", jobName, record["msg"]), fields...)
+}
+
+// runJob executes a job and collects its log and errors.
+// The child runs with --quiet and its own log file, so its stderr only carries errors.
+func runJob(ctx context.Context, executable string, job Job) (result jobResult) {
+	result = jobResult{Job: job}
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	logFile, err := os.CreateTemp("", "cleanup-job-*.log")
+	if err != nil {
+		result.Failed = true
+		result.Errors = append(result.Errors, fmt.Sprintf("could not create job log file: %v", err))
+		return result
+	}
+	logFile.Close()
+	defer os.Remove(logFile.Name())
+
+	args := append(jobArgs(job), "--quiet", "--log-file", logFile.Name(), "--log-format", "json")
+	logVerbose("[%s] Running: %s %s", job.Name, executable, strings.Join(args, " "))
+	var logText strings.Builder
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, executable, args...)
+	cmd.Stdout = &logText
+	cmd.Stderr = &stderr
+	// On Ctrl+C, interrupt the job like a user would, so it finishes the item it is deleting and
+	// writes its summary, and only kill it if it doesn't stop in time.
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = jobStopTimeout
+	runErr := cmd.Run()
+
+	if data, err := os.ReadFile(logFile.Name()); err == nil {
+		logText.Write(data)
+	}
+	result.Log = logText.String()
+
+	scanner := bufio.NewScanner(&stderr)
+	for scanner.Scan() {
+		line := strings.TrimSpace(ansiEscape.ReplaceAllString(scanner.Text(), ""))
+		if strings.HasPrefix(line, "[ERROR] ") || strings.HasPrefix(line, "Error: ") {
+			result.Errors = append(result.Errors, strings.TrimPrefix(strings.TrimPrefix(line, "[ERROR] "), "Error: "))
+		}
+	}
+	// Exit code 3 only means that a dry run found something, which is not a failure.
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) && exitErr.ExitCode() == exitFound {
+		result.Found = true
+		runErr = nil
+	}
+	if runErr != nil && len(result.Errors) == 0 {
+		result.Errors = append(result.Errors, runErr.Error())
+	}
+	result.Failed = runErr != nil || len(result.Errors) > 0
+	return result
+}
+
+// runJobs executes all jobs of a jobs file and prints a combined summary.
+// It returns an error if any job failed.
+func runJobs(ctx context.Context, jobFile *JobFile) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not locate the cleanup executable: %w", err)
+	}
+
+	parallel := jobFile.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	results := make([]jobResult, len(jobFile.Jobs))
+	var outputMutex sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallel)
+
+	for i, job := range jobFile.Jobs {
+		if ctx.Err() != nil {
+			results[i] = jobResult{Job: job, Failed: true, Errors: []string{"not started: " + ctx.Err().Error()}}
+			continue
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, job Job) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			outputMutex.Lock()
+			logInfo("▶️  Starting job %s (%s)", job.Name, job.Command)
+			outputMutex.Unlock()
+
+			results[i] = runJob(ctx, executable, job)
+
+			// Print each job's log as one block so parallel jobs don't interleave.
+			outputMutex.Lock()
+			defer outputMutex.Unlock()
+			scanner := bufio.NewScanner(strings.NewReader(results[i].Log))
+			for scanner.Scan() {
+				replayJobLog(job.Name, scanner.Text())
+			}
+			for _, e := range results[i].Errors {
+				addError(fmt.Errorf("job %s: %s", job.Name, e))
+			}
+			logInfo("%s Finished job %s in %s", jobStatusIcon(results[i]), job.Name, results[i].Duration.Round(time.Millisecond))
+		}(i, job)
+	}
+	wg.Wait()
+
+	failed := 0
+	logInfo("\n--- 🧾 Job Summary ---")
+	for _, result := range results {
+		line := fmt.Sprintf("%s %-20s %-7s %s", jobStatusIcon(result), result.Job.Name, result.Job.Command, result.Duration.Round(time.Millisecond))
+		if result.Job.Action != "" {
+			line += fmt.Sprintf("  action: %s", result.Job.Action)
+		}
+		if len(result.Errors) > 0 {
+			line += fmt.Sprintf("  (%d error(s))", len(result.Errors))
+		}
+		logInfo("%s", line)
+		if result.Failed {
+			failed++
+		}
+		if result.Found {
+			markItemsFound()
+		}
+	}
+	logInfo("----------------------")
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d job(s) failed", failed, len(results))
+	}
+	logInfo("✨ All %d job(s) completed successfully.", len(results))
+	return nil
+}
+
+func jobStatusIcon(result jobResult) string {
+	if result.Failed {
+		return "❌"
+	}
+	return "✅"
+}
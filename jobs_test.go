@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestDryRunDuplicateJobFinishes runs a duplicate search as a job with the default --keep prompt.
+// The job has no terminal to answer on, so it must pick the keepers itself instead of waiting.
+func TestDryRunDuplicateJobFinishes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs the cleanup executable")
+	}
+	executable := filepath.Join(t.TempDir(), "cleanup")
+	if runtime.GOOS == "windows" {
+		executable += ".exe"
+	}
+	if out, err := exec.Command("go", "build", "-o", executable, ".").CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("same content"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	job := Job{Name: "dups", Command: "find", Paths: []string{dir}, Flags: map[string]interface{}{"find-duplicates": true}, Action: "dry-run"}
+	result := runJob(ctx, executable, job)
+	if ctx.Err() != nil {
+		t.Fatal("the job did not finish; it is probably waiting for an answer")
+	}
+	if result.Failed || !result.Found {
+		t.Errorf("job failed = %v, found = %v, errors: %v\n%s", result.Failed, result.Found, result.Errors, result.Log)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("the dry run removed %s", name)
+		}
+	}
+}
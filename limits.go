@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"         // For error messages.
+	"sync/atomic" // For counting scanned items from parallel workers.
+
+	"github.com/spf13/cobra" // For registering the limit flags on the deleting commands.
+)
+
+// --- Deletion Limits and Circuit Breaker ---
+// A misconfigured run (a wrong path, a filter that matches everything) should fail loudly
+// instead of emptying a volume. The limits are checked before anything is deleted, and the
+// circuit breaker stops a run whose deletions start failing in bulk, e.g. on a dying disk.
+
+// scanTotals counts what the scan looked at, which --max-delete-percent is measured against.
+var scanTotals struct {
+	items atomic.Int64
+	bytes atomic.Int64
+}
+
+// countScanned records a scanned file or directory.
+func countScanned(size int64) {
+	scanTotals.items.Add(1)
+	scanTotals.bytes.Add(size)
+}
+
+// addDeletionLimitFlags registers the limit flags on a command that deletes.
+func addDeletionLimitFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&config.MaxDeleteCount, "max-delete-count", 0, "Abort without deleting anything if more than this many items would be deleted (0 = no limit).")
+	cmd.Flags().StringVar(&config.MaxDeleteSize, "max-delete-size", "", "Abort without deleting anything if more than this much data would be deleted (e.g., 50GB).")
+	cmd.Flags().Float64Var(&config.MaxDeletePercent, "max-delete-percent", 0, "Abort without deleting anything if more than this percentage of the scanned data would be deleted (0 = no limit).")
+	cmd.Flags().Float64Var(&config.MaxErrorRate, "max-error-rate", 50, "Stop deleting if more than this percentage of the last 20 deletions failed (0 = never stop).")
+}
+
+// limitError is returned when deleting the candidates would exceed a limit. A dry run reports
+// it as a warning instead, since it deletes nothing anyway.
+type limitError struct {
+	reason string
+}
+
+func (e *limitError) Error() string {
+	return "aborting: " + e.reason + ". Nothing was deleted"
+}
+
+func newLimitError(format string, v ...interface{}) error {
+	return &limitError{reason: fmt.Sprintf(format, v...)}
+}
+
+// checkDeletionLimits returns an error if deleting the given items would exceed a limit.
+func checkDeletionLimits(count int, totalSize int64) error {
+	if config.MaxDeleteCount > 0 && count > config.MaxDeleteCount {
+		return newLimitError("%d items would be deleted, more than --max-delete-count %d", count, config.MaxDeleteCount)
+	}
+	if config.MaxDeleteSize != "" {
+		limit, err := parseSize(config.MaxDeleteSize)
+		if err != nil {
+			return fmt.Errorf("invalid size for --max-delete-size: %w", err)
+		}
+		if totalSize > limit {
+			return newLimitError("%s would be deleted, more than --max-delete-size %s", formatBytes(totalSize), config.MaxDeleteSize)
+		}
+	}
+	if config.MaxDeletePercent > 0 {
+		// Measure by size where both sides are known, otherwise by the number of items,
+		// e.g. for empty folders, which have no size of their own.
+		var percent float64
+		scannedItems, scannedBytes := scanTotals.items.Load(), scanTotals.bytes.Load()
+		switch {
+		case totalSize > 0 && scannedBytes > 0:
+			percent = 100 * float64(totalSize) / float64(scannedBytes)
+		case scannedItems > 0:
+			percent = 100 * float64(count) / float64(scannedItems)
+		default:
+			// E.g. with --decisions, which applies a file instead of scanning. Ignoring the limit
+			// would delete without the safety net the user asked for.
+			return newLimitError("--max-delete-percent cannot be checked because nothing was scanned in this run (use --max-delete-count or --max-delete-size with --decisions)")
+		}
+		if percent > config.MaxDeletePercent {
+			return newLimitError("%.1f%% of the scanned data would be deleted, more than --max-delete-percent %g", percent, config.MaxDeletePercent)
+		}
+	}
+	return nil
+}
+
+// errorBreaker trips when too many of the most recent operations failed.
+type errorBreaker struct {
+	window   []bool // Outcome of the most recent operations; true means failed.
+	next     int
+	filled   int
+	failures int
+	maxRate  float64
+}
+
+// breakerWindow is the number of recent deletions the error rate is measured over, and
+// breakerMinSamples how many of them must have happened before the breaker can trip.
+const (
+	breakerWindow     = 20
+	breakerMinSamples = 10
+)
+
+func newErrorBreaker(maxRate float64) *errorBreaker {
+	return &errorBreaker{window: make([]bool, breakerWindow), maxRate: maxRate}
+}
+
+// record adds the outcome of an operation and reports whether the breaker tripped.
+func (b *errorBreaker) record(failed bool) bool {
+	if b.maxRate <= 0 {
+		return false
+	}
+	if b.filled == len(b.window) {
+		if b.window[b.next] {
+			b.failures--
+		}
+	} else {
+		b.filled++
+	}
+	b.window[b.next] = failed
+	if failed {
+		b.failures++
+	}
+	b.next = (b.next + 1) % len(b.window)
+	return b.filled >= breakerMinSamples && 100*float64(b.failures)/float64(b.filled) > b.maxRate
+}
+
+// rate returns the current failure rate in percent.
+func (b *errorBreaker) rate() float64 {
+	if b.filled == 0 {
+		return 0
+	}
+	return 100 * float64(b.failures) / float64(b.filled)
+}
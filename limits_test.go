@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestErrorBreakerNeedsMinSamples(t *testing.T) {
+	b := newErrorBreaker(50)
+	for i := 1; i < breakerMinSamples; i++ {
+		if b.record(true) {
+			t.Fatalf("tripped after %d failures, before %d samples", i, breakerMinSamples)
+		}
+	}
+	if !b.record(true) {
+		t.Error("did not trip once the minimum number of samples failed")
+	}
+}
+
+func TestErrorBreakerWindowForgetsOldFailures(t *testing.T) {
+	b := newErrorBreaker(50)
+	// Ten successes followed by ten failures is exactly 50%, which is not more than the limit.
+	for i := 0; i < 10; i++ {
+		b.record(false)
+	}
+	for i := 0; i < 10; i++ {
+		if b.record(true) {
+			t.Fatalf("tripped at %.0f%% after %d failures", b.rate(), i+1)
+		}
+	}
+	if b.rate() != 50 {
+		t.Fatalf("rate = %.1f, want 50", b.rate())
+	}
+	// Every further failure pushes an old success out of the window.
+	if !b.record(true) {
+		t.Errorf("did not trip at %.0f%%", b.rate())
+	}
+	if b.filled != breakerWindow || b.rate() != 55 {
+		t.Errorf("window holds %d outcomes at %.1f%%, want %d at 55%%", b.filled, b.rate(), breakerWindow)
+	}
+	// A run of successes pushes the failures out again.
+	for i := 0; i < breakerWindow; i++ {
+		b.record(false)
+	}
+	if b.rate() != 0 {
+		t.Errorf("rate = %.1f after %d successes, want 0", b.rate(), breakerWindow)
+	}
+}
+
+func TestErrorBreakerDisabled(t *testing.T) {
+	b := newErrorBreaker(0)
+	for i := 0; i < breakerWindow; i++ {
+		if b.record(true) {
+			t.Fatal("a breaker with --max-error-rate 0 tripped")
+		}
+	}
+}
+
+func TestDeletionLimitsWithoutScan(t *testing.T) {
+	defer func(old Config) { config = old }(config)
+	scanTotals.items.Store(0)
+	scanTotals.bytes.Store(0)
+	config.MaxDeletePercent = 10
+	if err := checkDeletionLimits(3, 300); err == nil {
+		t.Error("--max-delete-percent without a scan was ignored, want an error")
+	}
+	countScanned(1000)
+	if err := checkDeletionLimits(3, 300); err == nil {
+		t.Error("deleting 30% passed --max-delete-percent 10")
+	}
+	if err := checkDeletionLimits(1, 50); err != nil {
+		t.Errorf("deleting 5%% failed --max-delete-percent 10: %v", err)
+	}
+}
+
+// TestDryRunOverLimit checks that a dry run over a limit warns and lists the candidates
+// instead of aborting, while a real run aborts without deleting anything.
+func TestDryRunOverLimit(t *testing.T) {
+	defer func(old Config) { config = old }(config)
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a.log", "b.log", "c.log"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	config.MaxDeleteCount = 2
+
+	config.DryRun = true
+	if err := handleDeletion("files", paths, nil); err != nil {
+		t.Errorf("a dry run over --max-delete-count failed: %v", err)
+	}
+	config.DryRun, config.Force = false, true
+	err := handleDeletion("files", paths, nil)
+	var limit *limitError
+	if !errors.As(err, &limit) {
+		t.Errorf("a run over --max-delete-count = %v, want a limit error", err)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s was deleted: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package main
+
+import (
+	"os" // Provides the os.FileInfo interface.
+)
+
+// hardLinkCount is not tracked on this platform, so every file counts as a single link.
+func hardLinkCount(info os.FileInfo) uint64 {
+	return 1
+}
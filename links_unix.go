@@ -0,0 +1,16 @@
+//go:build unix
+
+package main
+
+import (
+	"os"      // Provides the os.FileInfo interface whose Sys() value carries the raw stat data.
+	"syscall" // Provides syscall.Stat_t with the hard link count.
+)
+
+// hardLinkCount returns the number of hard links to a file, or 1 if it cannot be determined.
+func hardLinkCount(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Nlink)
+	}
+	return 1
+}
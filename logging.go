@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"  // Required by the slog.Handler interface.
+	"fmt"      // For formatting log messages.
+	"io"       // For writing log output.
+	"log/slog" // For leveled, structured logging.
+	"os"       // For stderr and the log file.
+	"strings"  // For validating flag values and trimming messages.
+	"sync"     // For serialising console output.
+)
+
+// --- Logging ---
+// All log output goes through one slog.Logger. The console shows the familiar human-readable
+// messages, while the --log-file (or stderr with --log-format json and no log file) receives
+// structured records with a time, level and per-event fields such as path, action and bytes.
+
+// logLevels maps the values of --log-level to slog levels.
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// logLevelNames lists the valid values of --log-level in order.
+var logLevelNames = []string{"debug", "info", "warn", "error"}
+
+// configureLogger sets up the global logger based on config flags.
+// It only fails for invalid flag values; if the log file can't be opened, it logs to stderr.
+func configureLogger() error {
+	level, ok := logLevels[strings.ToLower(config.LogLevel)]
+	if !ok && config.LogLevel != "" {
+		return fmt.Errorf("invalid value for --log-level: %q. Allowed values are: [%s]", config.LogLevel, strings.Join(logLevelNames, ", "))
+	}
+	if !ok {
+		level = slog.LevelInfo
+	}
+	if config.Verbose {
+		level = slog.LevelDebug
+	}
+	switch config.LogFormat {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("invalid value for --log-format: %q. Allowed values are: [text, json]", config.LogFormat)
+	}
+
+	// With --quiet, the console still shows errors.
+	consoleLevel := level
+	if config.Quiet {
+		consoleLevel = slog.LevelError
+	}
+
+	var file *os.File
+	if config.LogFile != "" {
+		var err error
+		if file, err = os.OpenFile(config.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666); err != nil {
+			// Warn the user but don't crash.
+			fmt.Fprintf(os.Stderr, "⚠️  Could not open log file: %v. Logging to stderr only.\n", err)
+		}
+	}
+
+	var handlers []slog.Handler
+	if file != nil {
+		handlers = append(handlers,
+			&consoleHandler{w: os.Stderr, level: consoleLevel, mu: &sync.Mutex{}},
+			newStructuredHandler(file, level))
+	} else if config.LogFormat == "json" {
+		handlers = append(handlers, newStructuredHandler(os.Stderr, consoleLevel))
+	} else {
+		handlers = append(handlers, &consoleHandler{w: os.Stderr, level: consoleLevel, mu: &sync.Mutex{}})
+	}
+	// The run ID ties the log records to the run summary.
+	logger = slog.New(fanoutHandler(handlers)).With("run_id", runState.id)
+	return nil
+}
+
+// newStructuredHandler returns a text (key=value) or JSON handler, depending on --log-format.
+func newStructuredHandler(w io.Writer, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if config.LogFormat == "json" {
+		return trimmedHandler{slog.NewJSONHandler(w, opts)}
+	}
+	return trimmedHandler{slog.NewTextHandler(w, opts)}
+}
+
+// consoleHandler prints messages the way a person at a terminal wants to read them:
+// without timestamps or fields, verbose messages marked as such and errors in red.
+type consoleHandler struct {
+	w     io.Writer
+	level slog.Level
+	mu    *sync.Mutex
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var err error
+	switch {
+	case r.Level >= slog.LevelError:
+		_, err = fmt.Fprintf(h.w, "\033[31m[ERROR] %s\033[0m\n", r.Message)
+	case r.Level < slog.LevelInfo:
+		_, err = fmt.Fprintf(h.w, "[VERBOSE] %s\n", r.Message)
+	default:
+		_, err = fmt.Fprintln(h.w, r.Message)
+	}
+	return err
+}
+
+// The console doesn't show fields, so they are dropped.
+func (h *consoleHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *consoleHandler) WithGroup(string) slog.Handler      { return h }
+
+// trimmedHandler drops the blank lines, leading newlines and color codes used on the console,
+// which mean nothing in a structured log.
+type trimmedHandler struct {
+	slog.Handler
+}
+
+func (h trimmedHandler) Handle(ctx context.Context, r slog.Record) error {
+	msg := strings.TrimSpace(ansiEscape.ReplaceAllString(r.Message, ""))
+	if msg == "" {
+		return nil
+	}
+	trimmed := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		trimmed.AddAttrs(a)
+		return true
+	})
+	return h.Handler.Handle(ctx, trimmed)
+}
+
+func (h trimmedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return trimmedHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h trimmedHandler) WithGroup(name string) slog.Handler {
+	return trimmedHandler{h.Handler.WithGroup(name)}
+}
+
+// fanoutHandler passes each record on to every handler that wants it.
+type fanoutHandler []slog.Handler
+
+func (f fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range f {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (f fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (f fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
+// --- Logging Wrappers ---
+func logInfo(format string, v ...interface{}) {
+	logf(slog.LevelInfo, format, v...)
+}
+func logVerbose(format string, v ...interface{}) {
+	logf(slog.LevelDebug, format, v...)
+}
+func logError(format string, v ...interface{}) {
+	logf(slog.LevelError, format, v...)
+}
+
+// logf formats and logs a message. Errors raised before the logger is configured, e.g. while
+// reading the config file, still reach stderr.
+func logf(level slog.Level, format string, v ...interface{}) {
+	if logger == nil {
+		if level >= slog.LevelError {
+			fmt.Fprintf(os.Stderr, "\033[31m[ERROR] "+format+"\033[0m\n", v...)
+		}
+		return
+	}
+	if logger.Enabled(context.Background(), level) {
+		logger.Log(context.Background(), level, fmt.Sprintf(format, v...))
+	}
+}
+
+// logEvent logs a message with fields for the structured log, e.g. "path", p, "bytes", n.
+func logEvent(level slog.Level, msg string, fields ...any) {
+	if logger != nil {
+		logger.Log(context.Background(), level, msg, fields...)
+	}
+}
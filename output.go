@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/csv"  // For CSV tables.
+	"fmt"           // For error messages and table rows.
+	"html"          // For escaping values in HTML reports.
+	"io"            // For writing results to any writer.
+	"os"            // For stdout and result files.
+	"path/filepath" // For choosing the format of --output-file by extension.
+	"reflect"       // For writing result records of any type as a table.
+	"strings"       // For escaping table values.
+	"sync"          // For sharing the result writer.
+)
+
+// --- Result Output ---
+// Structured results go to --output-file if it is set, to the log file with --quiet (for backwards
+// compatibility), and to stdout otherwise, while logs always go to stderr and the --log-file. The
+// writer is opened once per run, so results, errors and the summary end up in the same file.
+
+// outputFormats lists the valid values of --output; "" is the human-readable default.
+var outputFormats = []string{"", "json", "csv", "tsv", "markdown", "html", "ndjson"}
+
+// outputFormatsByExt maps file extensions to formats, for --output-file without --output.
+var outputFormatsByExt = map[string]string{
+	".json":     "json",
+	".csv":      "csv",
+	".tsv":      "tsv",
+	".tab":      "tsv",
+	".md":       "markdown",
+	".markdown": "markdown",
+	".html":     "html",
+	".htm":      "html",
+	".ndjson":   "ndjson",
+	".jsonl":    "ndjson",
+}
+
+// resultOutput is the shared writer for structured results.
+var resultOutput struct {
+	sync.Mutex
+	w           io.Writer
+	file        *os.File
+	opened      bool
+	htmlStarted bool
+}
+
+// checkOutputFlags validates --output, --output-file, --print0 and --format and settles the format:
+// --print0 selects the path-list format, --format the template format, and --output-file alone
+// implies the format of its extension.
+func checkOutputFlags() error {
+	if config.Print0 && config.Format != "" {
+		return fmt.Errorf("--print0 cannot be combined with --format")
+	}
+	if config.Format != "" {
+		if config.OutputFormat != "" && config.OutputFormat != "template" {
+			return fmt.Errorf("--format cannot be combined with --output %s", config.OutputFormat)
+		}
+		config.OutputFormat = "template"
+		return nil
+	}
+	if config.Print0 {
+		if config.OutputFormat != "" && config.OutputFormat != "print0" {
+			return fmt.Errorf("--print0 cannot be combined with --output %s", config.OutputFormat)
+		}
+		config.OutputFormat = "print0"
+		return nil
+	}
+	if config.OutputFormat == "" && config.OutputFile != "" {
+		format, ok := outputFormatsByExt[strings.ToLower(filepath.Ext(config.OutputFile))]
+		if !ok {
+			return fmt.Errorf("cannot tell the format of --output-file %s from its extension. Use --output to choose one", config.OutputFile)
+		}
+		config.OutputFormat = format
+	}
+	if !contains(outputFormats, config.OutputFormat) {
+		return fmt.Errorf("invalid value for --output: %q. Allowed values are: [%s]", config.OutputFormat, strings.Join(outputFormats[1:], ", "))
+	}
+	return nil
+}
+
+// resultWriter returns where structured results go, opening it on first use.
+// It returns nil if structured output is off or has nowhere to go.
+func resultWriter() io.Writer {
+	resultOutput.Lock()
+	if resultOutput.opened {
+		defer resultOutput.Unlock()
+		return resultOutput.w
+	}
+	resultOutput.opened = true
+
+	var err error
+	switch {
+	case config.OutputFormat == "":
+	case config.OutputFile != "":
+		if resultOutput.file, err = os.Create(config.OutputFile); err != nil {
+			err = fmt.Errorf("failed to create output file: %w", err)
+		}
+	case !config.Quiet:
+		resultOutput.w = os.Stdout
+	case config.LogFile != "":
+		if resultOutput.file, err = os.OpenFile(config.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666); err != nil {
+			err = fmt.Errorf("failed to open log file for results: %w", err)
+		}
+	}
+	if resultOutput.file != nil {
+		resultOutput.w = resultOutput.file
+	}
+	w := resultOutput.w
+	resultOutput.Unlock()
+
+	addError(err)
+	return w
+}
+
+// closeResultWriter finishes an HTML report and closes the result file, if any.
+func closeResultWriter() {
+	resultOutput.Lock()
+	defer resultOutput.Unlock()
+	if resultOutput.htmlStarted {
+		io.WriteString(resultOutput.w, htmlEpilogue)
+		resultOutput.htmlStarted = false
+	}
+	if resultOutput.file != nil {
+		if err := resultOutput.file.Close(); err != nil {
+			logError("failed to close output file: %v", err)
+		}
+	}
+	resultOutput.w, resultOutput.file = nil, nil
+}
+
+// outputTable writes a slice of result records as a CSV, TSV, Markdown or HTML table with the
+// columns chosen by --fields.
+func outputTable(rows reflect.Value, writer io.Writer) {
+	headers := tableColumns(rows.Type().Elem())
+	records := make([][]string, 0, rows.Len())
+	for i := 0; i < rows.Len(); i++ {
+		records = append(records, columnValues(rows.Index(i), headers))
+	}
+	if err := writeTable(writer, "Results", headers, records); err != nil {
+		addError(fmt.Errorf("failed to write %s output: %w", config.OutputFormat, err))
+	}
+}
+
+// outputPrint0 writes the paths of a slice of result records for 'xargs -0'.
+func outputPrint0(rows reflect.Value, writer io.Writer) {
+	var paths []string
+	for i := 0; i < rows.Len(); i++ {
+		if path, ok := recordField(rows.Index(i), "path"); ok {
+			paths = append(paths, fmt.Sprint(path))
+		}
+	}
+	writePrint0(paths, writer)
+}
+
+// writePrint0 writes each path followed by a NUL byte.
+func writePrint0(paths []string, writer io.Writer) {
+	last := ""
+	for _, path := range paths {
+		// Rows of the same path follow each other, e.g. the age buckets of a report.
+		if path == last {
+			continue
+		}
+		last = path
+		fmt.Fprintf(writer, "%s\x00", path)
+	}
+}
+
+// writeTable writes a table in the current format. The title is shown as a heading in Markdown
+// (if not empty) and HTML; CSV and TSV tables have none.
+func writeTable(writer io.Writer, title string, headers []string, records [][]string) error {
+	switch config.OutputFormat {
+	case "tsv":
+		for _, record := range append([][]string{headers}, records...) {
+			escaped := make([]string, len(record))
+			for i, value := range record {
+				escaped[i] = escapeTSV(value)
+			}
+			if _, err := fmt.Fprintln(writer, strings.Join(escaped, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "markdown":
+		var b strings.Builder
+		if title != "" {
+			fmt.Fprintf(&b, "### %s\n\n", title)
+		}
+		b.WriteString(markdownRow(headers))
+		b.WriteString("|" + strings.Repeat(" --- |", len(headers)) + "\n")
+		for _, record := range records {
+			b.WriteString(markdownRow(record))
+		}
+		_, err := io.WriteString(writer, b.String())
+		return err
+	case "html":
+		return writeHTMLTable(writer, title, headers, records)
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Write(headers)
+	csvWriter.WriteAll(records)
+	return csvWriter.Error()
+}
+
+// escapeTSV escapes the characters that would break a tab-separated line.
+func escapeTSV(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(value)
+}
+
+// markdownRow formats one row of a Markdown table.
+func markdownRow(values []string) string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = strings.NewReplacer("|", `\|`, "\n", " ", "\r", "").Replace(value)
+	}
+	return "| " + strings.Join(escaped, " | ") + " |\n"
+}
+
+// recordRows lists the fields of a struct as name/value rows, named after their JSON tags.
+func recordRows(record interface{}) [][]string {
+	v := reflect.ValueOf(record)
+	columns := resultColumns(v.Type())
+	values := columnValues(v, columns)
+	rows := make([][]string, len(columns))
+	for i, name := range columns {
+		rows[i] = []string{name, values[i]}
+	}
+	return rows
+}
+
+// writeHTMLTable writes a table of an HTML report, starting the report on the first table.
+// Clicking a column header sorts the table by that column.
+func writeHTMLTable(writer io.Writer, title string, headers []string, records [][]string) error {
+	var b strings.Builder
+	resultOutput.Lock()
+	if !resultOutput.htmlStarted {
+		b.WriteString(htmlPrologue)
+		resultOutput.htmlStarted = true
+	}
+	resultOutput.Unlock()
+
+	fmt.Fprintf(&b, "<h2>%s</h2>\n<table>\n<thead><tr>", html.EscapeString(title))
+	for _, h := range headers {
+		fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(h))
+	}
+	b.WriteString("</tr></thead>\n<tbody>\n")
+	for _, record := range records {
+		b.WriteString("<tr>")
+		for _, value := range record {
+			fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(value))
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
+
+// htmlPrologue and htmlEpilogue frame the tables of an HTML report.
+const htmlPrologue = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Cleanup Report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f0f0f0; cursor: pointer; user-select: none; }
+th[data-order="asc"]::after { content: " ▲"; }
+th[data-order="desc"]::after { content: " ▼"; }
+tr:nth-child(even) td { background: #fafafa; }
+</style>
+</head>
+<body>
+<h1>Cleanup Report</h1>
+`
+
+const htmlEpilogue = `<script>
+document.querySelectorAll("th").forEach(function (th) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table"), body = table.tBodies[0], col = th.cellIndex;
+    var asc = th.dataset.order !== "asc";
+    table.querySelectorAll("th").forEach(function (other) { delete other.dataset.order; });
+    th.dataset.order = asc ? "asc" : "desc";
+    var rows = Array.prototype.slice.call(body.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[col].textContent, y = b.cells[col].textContent;
+      var c = x.localeCompare(y, undefined, { numeric: true, sensitivity: "base" });
+      return asc ? c : -c;
+    });
+    rows.forEach(function (row) { body.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,32 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors" // For reporting that ownership filters are unsupported.
+	"os"     // Provides the os.FileInfo interface.
+)
+
+// errNoOwnership is returned when an ownership filter is used on a platform without Unix-style owners.
+var errNoOwnership = errors.New("file ownership is not supported on this platform")
+
+// fileOwnership is unavailable on platforms without Unix-style owners, such as Windows.
+func fileOwnership(info os.FileInfo) (uid, gid uint32, ok bool) {
+	return 0, 0, false
+}
+
+func lookupUserName(uid uint32) string {
+	return ""
+}
+
+func lookupGroupName(gid uint32) string {
+	return ""
+}
+
+func lookupUserID(nameOrID string) (uint32, error) {
+	return 0, errNoOwnership
+}
+
+func lookupGroupID(nameOrID string) (uint32, error) {
+	return 0, errNoOwnership
+}
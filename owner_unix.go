@@ -0,0 +1,90 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"     // For wrapping lookup errors.
+	"os"      // Provides the os.FileInfo interface whose Sys() value carries the raw stat data.
+	"os/user" // For resolving numeric user and group IDs into names and back.
+	"strconv" // For converting numeric IDs into the string form os/user expects.
+	"sync"    // For guarding the name caches, which are shared by the scanner's worker goroutines.
+	"syscall" // Provides syscall.Stat_t with the owning user and group IDs.
+)
+
+var (
+	userNameCache  = make(map[uint32]string) // Resolved user names by UID; "" marks an unknown UID.
+	groupNameCache = make(map[uint32]string) // Resolved group names by GID; "" marks an unknown GID.
+	nameCacheMutex sync.Mutex                // Protects both caches.
+)
+
+// fileOwnership returns the numeric owner and group of a file.
+func fileOwnership(info os.FileInfo) (uid, gid uint32, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return st.Uid, st.Gid, true
+}
+
+// lookupUserName resolves a UID to a user name, returning "" if no such user exists.
+// Results are cached because the same few owners are looked up for every scanned file.
+func lookupUserName(uid uint32) string {
+	nameCacheMutex.Lock()
+	defer nameCacheMutex.Unlock()
+	if name, ok := userNameCache[uid]; ok {
+		return name
+	}
+	var name string
+	if u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10)); err == nil {
+		name = u.Username
+	}
+	userNameCache[uid] = name
+	return name
+}
+
+// lookupGroupName resolves a GID to a group name, returning "" if no such group exists.
+func lookupGroupName(gid uint32) string {
+	nameCacheMutex.Lock()
+	defer nameCacheMutex.Unlock()
+	if name, ok := groupNameCache[gid]; ok {
+		return name
+	}
+	var name string
+	if g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10)); err == nil {
+		name = g.Name
+	}
+	groupNameCache[gid] = name
+	return name
+}
+
+// lookupUserID resolves a user name or numeric UID given on the command line.
+func lookupUserID(nameOrID string) (uint32, error) {
+	if id, err := strconv.ParseUint(nameOrID, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+	u, err := user.Lookup(nameOrID)
+	if err != nil {
+		return 0, fmt.Errorf("unknown user %q: %w", nameOrID, err)
+	}
+	id, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("user %q has a non-numeric UID %q", nameOrID, u.Uid)
+	}
+	return uint32(id), nil
+}
+
+// lookupGroupID resolves a group name or numeric GID given on the command line.
+func lookupGroupID(nameOrID string) (uint32, error) {
+	if id, err := strconv.ParseUint(nameOrID, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+	g, err := user.LookupGroup(nameOrID)
+	if err != nil {
+		return 0, fmt.Errorf("unknown group %q: %w", nameOrID, err)
+	}
+	id, err := strconv.ParseUint(g.Gid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("group %q has a non-numeric GID %q", nameOrID, g.Gid)
+	}
+	return uint32(id), nil
+}
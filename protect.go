@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"           // For error messages.
+	"os"            // For locating the home directory and checking for marker files.
+	"path/filepath" // For normalising and comparing paths.
+	"runtime"       // For the platform-specific built-in lists.
+	"strings"       // For case-insensitive comparisons.
+	"sync"          // For caching marker lookups from parallel workers.
+)
+
+// --- Protected Paths ---
+// Some paths must never be cleaned up, whatever a scan produced: system directories, the home
+// directory itself, and any tree containing a '.cleanup-protect' marker file. Deleting commands
+// refuse to scan them, skip them while scanning, and check every path again before deleting.
+// --i-know-what-im-doing turns all of this off.
+
+// protectMarker is the name of the marker file that protects the directory containing it.
+const protectMarker = ".cleanup-protect"
+
+// pathGuard decides whether a path is protected.
+type pathGuard struct {
+	exact    []string        // Paths that can't be targeted or deleted themselves; their contents can.
+	trees    []string        // Paths that are protected together with everything below them.
+	markers  map[string]bool // Cache of directories known to contain (or not) a marker file.
+	mu       sync.Mutex
+	disabled bool
+}
+
+var (
+	guard     *pathGuard
+	guardOnce sync.Once
+)
+
+// getPathGuard returns the guard for this run, built from the built-in list and 'protected-paths'.
+func getPathGuard() *pathGuard {
+	guardOnce.Do(func() {
+		exact, trees := builtinProtectedPaths()
+		guard = newPathGuard(exact, append(trees, config.ProtectedPaths...), config.IKnowWhatImDoing)
+	})
+	return guard
+}
+
+// newPathGuard builds a guard from lists of exactly protected paths and protected trees.
+func newPathGuard(exact, trees []string, disabled bool) *pathGuard {
+	g := &pathGuard{markers: make(map[string]bool), disabled: disabled}
+	for _, p := range exact {
+		g.exact = append(g.exact, normalizeProtectedPath(p))
+	}
+	for _, p := range trees {
+		if p != "" {
+			g.trees = append(g.trees, normalizeProtectedPath(p))
+		}
+	}
+	return g
+}
+
+// builtinProtectedPaths returns the paths that are always protected on this platform.
+func builtinProtectedPaths() (exact, trees []string) {
+	home, _ := os.UserHomeDir()
+	if home != "" {
+		exact = append(exact, home)
+		trees = append(trees, filepath.Join(home, ".ssh"), filepath.Join(home, ".gnupg"))
+	}
+
+	if runtime.GOOS == "windows" {
+		exact = append(exact, filepath.Join(os.Getenv("SystemDrive")+`\`, "Users"))
+		for _, env := range []string{"SystemRoot", "ProgramFiles", "ProgramFiles(x86)", "ProgramData"} {
+			if dir := os.Getenv(env); dir != "" {
+				trees = append(trees, dir)
+			}
+		}
+		return exact, trees
+	}
+
+	exact = append(exact, "/home", "/Users", "/root", "/mnt", "/media", "/Volumes", "/var", "/opt")
+	trees = append(trees, "/bin", "/boot", "/dev", "/etc", "/lib", "/lib32", "/lib64", "/libx32",
+		"/proc", "/run", "/sbin", "/snap", "/sys", "/usr", "/var/lib", "/var/db")
+	if runtime.GOOS == "darwin" {
+		trees = append(trees, "/System", "/Library", "/Applications", "/private/etc", "/private/var/db")
+	}
+	return exact, trees
+}
+
+// resolveProtectedPath makes a path absolute and resolves symlinks in the directories above it,
+// so that a path reached through a link into a protected tree is protected too. The last
+// component is kept as it is: removing a symlink doesn't touch what it points to.
+func resolveProtectedPath(p string) string {
+	if abs, err := filepath.Abs(expandHome(p)); err == nil {
+		p = abs
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(p)); err == nil {
+		p = filepath.Join(dir, filepath.Base(p))
+	}
+	return p
+}
+
+// normalizeProtectedPath resolves a path and, on case-insensitive platforms, lowercases it.
+func normalizeProtectedPath(p string) string {
+	p = resolveProtectedPath(p)
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		p = strings.ToLower(p)
+	}
+	return p
+}
+
+// protectedReason explains why a path is protected, or returns "" if it is not.
+// Only the path and its ancestors are checked, not what lies below it.
+func (g *pathGuard) protectedReason(path string) string {
+	if g.disabled {
+		return ""
+	}
+	p := normalizeProtectedPath(path)
+	if filepath.Dir(p) == p {
+		return "it is a filesystem root"
+	}
+	for _, exact := range g.exact {
+		if p == exact {
+			return "it is a protected location"
+		}
+	}
+	for _, tree := range g.trees {
+		if isWithin(tree, p) {
+			return fmt.Sprintf("it is inside the protected path %s", tree)
+		}
+	}
+	for dir := resolveProtectedPath(path); ; dir = filepath.Dir(dir) {
+		if g.hasMarker(dir) {
+			return fmt.Sprintf("%s contains a %s file", dir, protectMarker)
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// hasMarker reports whether a directory contains a marker file. Results are cached.
+func (g *pathGuard) hasMarker(dir string) bool {
+	g.mu.Lock()
+	found, cached := g.markers[dir]
+	g.mu.Unlock()
+	if cached {
+		return found
+	}
+	_, err := os.Lstat(filepath.Join(dir, protectMarker))
+	found = err == nil
+	g.mu.Lock()
+	g.markers[dir] = found
+	g.mu.Unlock()
+	return found
+}
+
+// checkTargets rejects target paths that a deleting command must not scan.
+func (g *pathGuard) checkTargets(targetDirs []string) error {
+	for _, dir := range targetDirs {
+		reason := g.protectedReason(dir)
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil && reason == "" {
+			// A target that is itself a link is scanned where it points to.
+			reason = g.protectedReason(resolved)
+		}
+		if reason != "" {
+			return fmt.Errorf("refusing to clean up %s because %s. Use --i-know-what-im-doing to override", dir, reason)
+		}
+	}
+	return nil
+}
+
+// skipDir reports whether a scan for deletion should leave out a directory and everything below it.
+func (g *pathGuard) skipDir(dir string) bool {
+	if g.disabled {
+		return false
+	}
+	if g.hasMarker(dir) {
+		logVerbose("Skipping protected directory (contains %s): %s", protectMarker, dir)
+		return true
+	}
+	p := normalizeProtectedPath(dir)
+	for _, tree := range g.trees {
+		if p == tree {
+			logVerbose("Skipping protected directory: %s", dir)
+			return true
+		}
+	}
+	return false
+}
+
+// checkDeletion returns an error if a path must not be deleted: if it is protected itself or,
+// for a directory, if a marker file exists anywhere below it.
+func (g *pathGuard) checkDeletion(path string) error {
+	if reason := g.protectedReason(path); reason != "" {
+		return fmt.Errorf("refusing to delete %s because %s", path, reason)
+	}
+	if g.disabled {
+		return nil
+	}
+	var marked string
+	_ = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && d.Name() == protectMarker {
+			marked = filepath.Dir(p)
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if marked != "" {
+		return fmt.Errorf("refusing to delete %s because %s below it contains a %s file", path, marked, protectMarker)
+	}
+	return nil
+}
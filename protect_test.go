@@ -0,0 +1,188 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// protectedTree creates a home-like directory, a protected tree and a directory with a marker
+// file two levels above its contents, and returns a guard for them.
+func protectedTree(t *testing.T, disabled bool) (base string, g *pathGuard) {
+	t.Helper()
+	base = t.TempDir()
+	for _, dir := range []string{"home/me/src", "usr/lib", "data/project/build/cache"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(base, "data", "project", protectMarker), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g = newPathGuard([]string{filepath.Join(base, "home", "me")}, []string{filepath.Join(base, "usr")}, disabled)
+	return base, g
+}
+
+func TestProtectedReason(t *testing.T) {
+	base, g := protectedTree(t, false)
+	tests := []struct {
+		path string
+		want string // A part of the reason, or "" if the path is not protected.
+	}{
+		{"home/me", "protected location"},
+		{"home/me/src", ""},
+		{"home", ""},
+		{"usr", "inside the protected path"},
+		{"usr/lib/libc.so", "inside the protected path"},
+		{"usrlocal", ""},
+		{"data", ""},
+		{"data/project", protectMarker},
+		{"data/project/build/cache", protectMarker},
+	}
+	for _, tt := range tests {
+		got := g.protectedReason(filepath.Join(base, filepath.FromSlash(tt.path)))
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("protectedReason(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if got := g.protectedReason(string(filepath.Separator)); got == "" {
+		t.Error("the filesystem root is not protected")
+	}
+}
+
+// TestBuiltinProtectedPaths checks that home and /var are only protected themselves, while
+// system trees such as /usr are protected with everything below them.
+func TestBuiltinProtectedPaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the built-in lists below are for Unix systems")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	exact, trees := builtinProtectedPaths()
+	g := newPathGuard(exact, trees, false)
+	tests := []struct {
+		path      string
+		protected bool
+	}{
+		{home, true},
+		{filepath.Join(home, "Downloads", "old"), false},
+		{filepath.Join(home, ".ssh", "id_ed25519"), true},
+		{"/var", true},
+		{"/var/tmp/build", false},
+		{"/usr", true},
+		{"/usr/local/lib/x", true},
+		{"/etc/hosts", true},
+	}
+	for _, tt := range tests {
+		if got := g.protectedReason(tt.path) != ""; got != tt.protected {
+			t.Errorf("protectedReason(%s) protected = %v, want %v", tt.path, got, tt.protected)
+		}
+	}
+}
+
+func TestCheckDeletion(t *testing.T) {
+	base, g := protectedTree(t, false)
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"home/me/src", ""},
+		{"home/me", "protected location"},
+		{"home", ""},
+		{"usr/lib", "inside the protected path"},
+		// The marker is below these directories, so deleting them would delete it too.
+		{"data", "below it contains"},
+		{".", "below it contains"},
+		{"data/project/build", "contains a " + protectMarker},
+	}
+	for _, tt := range tests {
+		err := g.checkDeletion(filepath.Join(base, filepath.FromSlash(tt.path)))
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("checkDeletion(%s) = %v, want nil", tt.path, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("checkDeletion(%s) = %v, want an error containing %q", tt.path, err, tt.want)
+		}
+	}
+
+	if !g.skipDir(filepath.Join(base, "data", "project")) || !g.skipDir(filepath.Join(base, "usr")) {
+		t.Error("a scan does not skip a directory with a marker or a protected tree")
+	}
+	if g.skipDir(filepath.Join(base, "data")) || g.skipDir(filepath.Join(base, "usr", "lib")) {
+		t.Error("a scan skips a directory that is only protected through a parent")
+	}
+}
+
+// TestGuardOverride checks that --i-know-what-im-doing turns off all checks.
+func TestGuardOverride(t *testing.T) {
+	base, g := protectedTree(t, true)
+	for _, path := range []string{string(filepath.Separator), "home/me", "usr/lib", "data", "data/project/build"} {
+		path = filepath.Join(base, filepath.FromSlash(path))
+		if reason := g.protectedReason(path); reason != "" {
+			t.Errorf("protectedReason(%s) = %q with the override", path, reason)
+		}
+		if err := g.checkDeletion(path); err != nil {
+			t.Errorf("checkDeletion(%s) = %v with the override", path, err)
+		}
+		if g.skipDir(path) {
+			t.Errorf("skipDir(%s) with the override", path)
+		}
+	}
+	if err := g.checkTargets([]string{filepath.Join(base, "usr")}); err != nil {
+		t.Errorf("checkTargets = %v with the override", err)
+	}
+}
+
+func TestGuardSymlinkedRoots(t *testing.T) {
+	base, g := protectedTree(t, false)
+	links := map[string]string{"usr-link": "usr", "project-link": "data/project", "lib-link": "usr/lib"}
+	for link, target := range links {
+		if err := os.Symlink(filepath.Join(base, filepath.FromSlash(target)), filepath.Join(base, link)); err != nil {
+			t.Skipf("cannot create symlinks: %v", err)
+		}
+	}
+
+	// Paths below a link are protected like the paths the link points to.
+	for _, path := range []string{"usr-link/lib", "project-link/build"} {
+		if g.protectedReason(filepath.Join(base, filepath.FromSlash(path))) == "" {
+			t.Errorf("%s is not protected", path)
+		}
+	}
+	// A target that is a link into a protected tree is rejected.
+	for _, link := range []string{"usr-link", "lib-link", "project-link"} {
+		if err := g.checkTargets([]string{filepath.Join(base, link)}); err == nil {
+			t.Errorf("checkTargets(%s) accepted a link into a protected path", link)
+		}
+	}
+	// Deleting the link itself leaves what it points to alone.
+	if err := g.checkDeletion(filepath.Join(base, "lib-link")); err != nil {
+		t.Errorf("checkDeletion of a link = %v, want nil", err)
+	}
+}
+
+func TestGuardRelativeRoots(t *testing.T) {
+	base, g := protectedTree(t, false)
+	t.Chdir(filepath.Join(base, "data", "project", "build"))
+
+	for _, path := range []string{".", "cache", ".."} {
+		if reason := g.protectedReason(path); !strings.Contains(reason, protectMarker) {
+			t.Errorf("protectedReason(%s) = %q, want the marker above it", path, reason)
+		}
+	}
+	if err := g.checkTargets([]string{"../../../usr/lib"}); err == nil {
+		t.Error("checkTargets accepted a relative path into a protected tree")
+	}
+	if err := g.checkDeletion("../.."); err == nil {
+		t.Error("checkDeletion accepted a relative path with a marker below it")
+	}
+	if err := g.checkDeletion("../../../home/me/src"); err != nil {
+		t.Errorf("checkDeletion of a relative path = %v, want nil", err)
+	}
+}
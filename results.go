@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"     // For formatting table cells and error messages.
+	"reflect" // For listing the fields of a record type.
+	"strings" // For formatting lists and flag values.
+	"time"    // For formatting times in tables.
+)
+
+// --- Result Records ---
+// Every command reports its results as typed records. JSON and NDJSON output encode them as they
+// are, and the table formats (CSV, TSV, Markdown and HTML) have one column per field, named and
+// ordered as in JSON; --fields selects and orders the columns. The records form a versioned schema:
+// fields may be added within a version, but renaming or removing a field or changing its type
+// needs a new resultSchemaVersion. 'cleanup schema' prints the JSON Schema of each output.
+
+// resultSchemaVersion is the version of the result and summary schemas. It is reported in the run summary.
+const resultSchemaVersion = 1
+
+// emptyDirResult is an empty folder found by 'empty'.
+type emptyDirResult struct {
+	Path    string    `json:"path" desc:"Path of the empty folder."`
+	Root    string    `json:"root" desc:"Target path the folder was found under."`
+	Size    int64     `json:"size" desc:"Total size in bytes of the ignored files left in the folder; 0 if there are none."`
+	ModTime time.Time `json:"modified" desc:"Last modification time of the folder."`
+}
+
+// fileMatchResult is a file found by 'find' by size, age, date or owner.
+type fileMatchResult struct {
+	Path    string    `json:"path" desc:"Path of the file."`
+	Root    string    `json:"root" desc:"Target path the file was found under."`
+	Size    int64     `json:"size" desc:"Size in bytes."`
+	ModTime time.Time `json:"modified" desc:"Last modification time."`
+	Owner   string    `json:"owner" desc:"Name (or ID) of the owning user; empty where unsupported."`
+	Group   string    `json:"group" desc:"Name (or ID) of the owning group; empty where unsupported."`
+	Mode    string    `json:"mode" desc:"Permission bits in octal, e.g. 0644."`
+}
+
+// largeDirResult is one of the largest folders found by 'large'.
+type largeDirResult struct {
+	Path          string    `json:"path" desc:"Path of the folder."`
+	Root          string    `json:"root" desc:"Target path the folder was found under."`
+	Size          int64     `json:"size" desc:"Total size of the folder's files in bytes."`
+	SizeFormatted string    `json:"size_formatted" desc:"The size in human-readable form, e.g. 1.5 GB."`
+	ModTime       time.Time `json:"modified" desc:"Last modification time of the folder itself."`
+}
+
+// reportResult is one age bucket of a top-level folder in 'report'.
+type reportResult struct {
+	Path          string `json:"path" desc:"Path of the top-level folder."`
+	Root          string `json:"root" desc:"Target path the folder is in."`
+	Bucket        string `json:"bucket" desc:"Age range of the bucket: 0-7d, 7-30d, 30-90d, 90d-1y or >1y."`
+	ModifiedFiles int64  `json:"modified_files" desc:"Number of files last modified in the age range."`
+	ModifiedBytes int64  `json:"modified_bytes" desc:"Their total size in bytes."`
+	AccessedFiles int64  `json:"accessed_files" desc:"Number of files last accessed in the age range."`
+	AccessedBytes int64  `json:"accessed_bytes" desc:"Their total size in bytes."`
+}
+
+// duplicateSetResult is a set of duplicates found by 'find -D', '--duplicate-dirs' or '--similar-images'.
+// JSON and NDJSON output report sets; the table formats list their copies instead.
+type duplicateSetResult struct {
+	Group                int      `json:"group" desc:"Number of the set, largest waste of space first."`
+	Hash                 string   `json:"hash" desc:"Content, tree or perceptual hash shared by the copies."`
+	Size                 int64    `json:"size" desc:"Size of one copy in bytes."`
+	Count                int      `json:"count" desc:"Number of copies."`
+	Paths                []string `json:"paths" desc:"All copies, sorted."`
+	Keep                 string   `json:"keep" desc:"The copy that is kept; empty if the set was skipped."`
+	Reclaimable          int64    `json:"reclaimable" desc:"Bytes freed by removing the other copies."`
+	ReclaimableFormatted string   `json:"reclaimable_formatted" desc:"The reclaimable space in human-readable form."`
+}
+
+// duplicateCopyResult is one copy of a duplicate set: a row of the table formats.
+type duplicateCopyResult struct {
+	Group       int       `json:"group" desc:"Number of the set the copy belongs to."`
+	Hash        string    `json:"hash" desc:"Hash shared by the copies of the set."`
+	Size        int64     `json:"size" desc:"Size of one copy in bytes."`
+	Count       int       `json:"count" desc:"Number of copies in the set."`
+	Path        string    `json:"path" desc:"Path of the copy."`
+	Root        string    `json:"root" desc:"Target path the copy was found under."`
+	ModTime     time.Time `json:"modified" desc:"Last modification time of the copy."`
+	Action      string    `json:"action" desc:"What happens to the copy: keep, delete or skip."`
+	Reclaimable int64     `json:"reclaimable" desc:"Bytes freed by removing the set's other copies."`
+}
+
+// duplicateReport is the JSON document of the duplicate modes of 'find'.
+type duplicateReport struct {
+	Groups                    []duplicateSetResult `json:"groups" desc:"The duplicate sets."`
+	TotalGroups               int                  `json:"total_groups" desc:"Number of sets."`
+	TotalReclaimable          int64                `json:"total_reclaimable" desc:"Bytes freed by removing all copies that are not kept."`
+	TotalReclaimableFormatted string               `json:"total_reclaimable_formatted" desc:"The reclaimable space in human-readable form."`
+}
+
+// resultSchema describes the output of a command.
+type resultSchema struct {
+	name        string
+	description string
+	document    reflect.Type // The JSON output.
+	row         reflect.Type // A row of the table formats; nil if the output has no table.
+}
+
+// resultSchemas lists the outputs of all commands, for 'cleanup schema' and --fields.
+var resultSchemas = []resultSchema{
+	{"empty", "Empty folders found by 'cleanup empty'.",
+		reflect.TypeOf([]emptyDirResult{}), reflect.TypeOf(emptyDirResult{})},
+	{"find", "Files found by 'cleanup find' by size, age, date or owner.",
+		reflect.TypeOf([]fileMatchResult{}), reflect.TypeOf(fileMatchResult{})},
+	{"find-duplicates", "Duplicate sets found by 'cleanup find -D', '--duplicate-dirs' or '--similar-images'. The table formats have one row per copy.",
+		reflect.TypeOf(duplicateReport{}), reflect.TypeOf(duplicateCopyResult{})},
+	{"large", "The largest folders found by 'cleanup large'.",
+		reflect.TypeOf([]largeDirResult{}), reflect.TypeOf(largeDirResult{})},
+	{"report", "Age buckets of the top-level folders found by 'cleanup report'.",
+		reflect.TypeOf([]reportResult{}), reflect.TypeOf(reportResult{})},
+	{"summary", "The run summary: the last NDJSON event and the content of --summary-file.",
+		reflect.TypeOf(runSummary{}), nil},
+}
+
+// schemaNames lists the names of the result schemas.
+func schemaNames() []string {
+	var names []string
+	for _, s := range resultSchemas {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// findSchema returns the schema with the given name, or nil.
+func findSchema(name string) *resultSchema {
+	for i := range resultSchemas {
+		if resultSchemas[i].name == name {
+			return &resultSchemas[i]
+		}
+	}
+	return nil
+}
+
+// commandSchema returns the schema of the results of a command with the current flags, or nil
+// if the command reports none.
+func commandSchema(command string) *resultSchema {
+	if command == "find" && (config.FindDuplicates || config.DuplicateDirs || config.SimilarImages || config.Decisions != "") {
+		command = "find-duplicates"
+	}
+	return findSchema(command)
+}
+
+// checkFieldsFlag validates --fields against the columns of the command's results.
+func checkFieldsFlag(command string) error {
+	schema := commandSchema(command)
+	if len(config.Fields) == 0 || schema == nil || schema.row == nil {
+		return nil
+	}
+	columns := resultColumns(schema.row)
+	for _, field := range config.Fields {
+		if !contains(columns, field) {
+			return fmt.Errorf("invalid value for --fields: %q. Allowed values are: [%s]", field, strings.Join(columns, ", "))
+		}
+	}
+	return nil
+}
+
+// resultColumns returns the columns of a record type: the JSON names of its fields, in order.
+func resultColumns(t reflect.Type) []string {
+	var columns []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// tableColumns returns the columns to show for a record type: those chosen with --fields, or all.
+func tableColumns(t reflect.Type) []string {
+	if len(config.Fields) > 0 {
+		return config.Fields
+	}
+	return resultColumns(t)
+}
+
+// jsonName returns the name of a field in JSON, or "" if it isn't encoded.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" || !field.IsExported() {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// recordField returns the value of the field of a record with the given JSON name.
+func recordField(record reflect.Value, name string) (interface{}, bool) {
+	t := record.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if jsonName(t.Field(i)) == name {
+			return record.Field(i).Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// columnValues formats the given columns of a record as table cells.
+// Times are written as RFC 3339 and lists separated by commas.
+func columnValues(record reflect.Value, columns []string) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		value, ok := recordField(record, column)
+		if !ok {
+			continue
+		}
+		switch val := value.(type) {
+		case time.Time:
+			values[i] = val.Format(time.RFC3339)
+		case []string:
+			values[i] = strings.Join(val, ", ")
+		default:
+			values[i] = fmt.Sprint(val)
+		}
+	}
+	return values
+}
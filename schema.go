@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"     // For error messages and schema IDs.
+	"reflect" // For deriving the schemas from the record types.
+	"strings" // For reading struct tags.
+	"time"    // For recognising timestamps.
+)
+
+// --- JSON Schemas ---
+// The schemas are derived from the result record types, so they can't drift from the output.
+// Descriptions come from the 'desc' tags of the fields.
+
+// jsonSchema returns the JSON Schema document of the output.
+func (s resultSchema) jsonSchema() map[string]interface{} {
+	schema := typeSchema(s.document)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = fmt.Sprintf("urn:cleanup:schema:v%d:%s", resultSchemaVersion, s.name)
+	schema["title"] = s.name
+	schema["description"] = s.description
+	return schema
+}
+
+// typeSchema returns the JSON Schema of a Go type as encoding/json writes it.
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := jsonName(field)
+			if name == "" {
+				continue
+			}
+			property := typeSchema(field.Type)
+			if desc := field.Tag.Get("desc"); desc != "" {
+				property["description"] = desc
+			}
+			properties[name] = property
+			if !strings.Contains(field.Tag.Get("json"), ",omitempty") {
+				required = append(required, name)
+			}
+		}
+		return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,20 @@
+//go:build darwin || freebsd || netbsd
+
+package main
+
+import (
+	"os"      // Provides the os.FileInfo interface whose Sys() value carries the raw stat data.
+	"syscall" // Provides syscall.Stat_t, which uses the *timespec field names on these systems.
+	"time"    // For converting raw timespec values into time.Time.
+)
+
+// statTimes extracts the timestamps macOS, FreeBSD and NetBSD expose through stat(2).
+func statTimes(info os.FileInfo) fileTimes {
+	times := fileTimes{Modified: info.ModTime()}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		times.Accessed = time.Unix(st.Atimespec.Unix())
+		times.Changed = time.Unix(st.Ctimespec.Unix())
+		times.Born = unixBirthTime(st.Birthtimespec.Unix())
+	}
+	return times
+}
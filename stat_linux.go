@@ -0,0 +1,68 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"         // For reading the mount table line by line.
+	"os"            // Provides the os.FileInfo interface whose Sys() value carries the raw stat data.
+	"path/filepath" // For matching the target path against mount points.
+	"strings"       // For splitting mount table fields and option lists.
+	"syscall"       // Provides syscall.Stat_t, the Linux layout of the stat structure.
+	"time"          // For converting raw timespec values into time.Time.
+
+	"golang.org/x/sys/unix" // Provides statx(2), the only way to read a file's birth time on Linux.
+)
+
+// statTimes extracts the timestamps Linux exposes through stat(2).
+// The birth time is not part of stat(2); see statxBirthTime.
+func statTimes(info os.FileInfo) fileTimes {
+	times := fileTimes{Modified: info.ModTime()}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		times.Accessed = time.Unix(st.Atim.Unix())
+		times.Changed = time.Unix(st.Ctim.Unix())
+	}
+	return times
+}
+
+// statxBirthTime reads a file's birth time via statx(2).
+// It returns the zero time if the kernel or filesystem does not record it.
+func statxBirthTime(path string) time.Time {
+	var stx unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, path, unix.AT_SYMLINK_NOFOLLOW, unix.STATX_BTIME, &stx); err != nil {
+		return time.Time{}
+	}
+	if stx.Mask&unix.STATX_BTIME == 0 {
+		return time.Time{}
+	}
+	return time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec))
+}
+
+// isNoatimeMount reports whether the filesystem holding path is mounted with 'noatime',
+// in which case access times are never updated and cannot be trusted.
+func isNoatimeMount(path string) bool {
+	file, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	// The longest mount point that is a prefix of the path is the one the path lives on.
+	var bestMount string
+	var bestOptions []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		mountPoint := fields[1]
+		if mountPoint != "/" && path != mountPoint && !strings.HasPrefix(path, mountPoint+string(filepath.Separator)) {
+			continue
+		}
+		if len(mountPoint) >= len(bestMount) {
+			bestMount = mountPoint
+			bestOptions = strings.Split(fields[3], ",")
+		}
+	}
+	return contains(bestOptions, "noatime")
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package main
+
+import (
+	"time" // For the time.Time return value.
+)
+
+// statxBirthTime is only needed on Linux, where stat(2) lacks the birth time.
+// Other platforms report it through statTimes when they support it at all.
+func statxBirthTime(path string) time.Time {
+	return time.Time{}
+}
+
+// isNoatimeMount is only implemented for Linux mount tables.
+func isNoatimeMount(path string) bool {
+	return false
+}
@@ -0,0 +1,20 @@
+//go:build openbsd
+
+package main
+
+import (
+	"os"      // Provides the os.FileInfo interface whose Sys() value carries the raw stat data.
+	"syscall" // Provides syscall.Stat_t, the OpenBSD layout of the stat structure.
+	"time"    // For converting raw timespec values into time.Time.
+)
+
+// statTimes extracts the timestamps OpenBSD exposes through stat(2).
+func statTimes(info os.FileInfo) fileTimes {
+	times := fileTimes{Modified: info.ModTime()}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		times.Accessed = time.Unix(st.Atim.Unix())
+		times.Changed = time.Unix(st.Ctim.Unix())
+		times.Born = unixBirthTime(st.X__st_birthtim.Unix())
+	}
+	return times
+}
@@ -0,0 +1,12 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+
+package main
+
+import (
+	"os" // Provides the os.FileInfo interface.
+)
+
+// statTimes falls back to the modification time on platforms without a known stat layout.
+func statTimes(info os.FileInfo) fileTimes {
+	return fileTimes{Modified: info.ModTime()}
+}
@@ -0,0 +1,20 @@
+//go:build aix || dragonfly || solaris
+
+package main
+
+import (
+	"os"      // Provides the os.FileInfo interface whose Sys() value carries the raw stat data.
+	"syscall" // Provides syscall.Stat_t for the remaining Unix-like systems.
+	"time"    // For converting raw timespec values into time.Time.
+)
+
+// statTimes extracts the timestamps the remaining Unix-like systems expose through stat(2).
+// None of them record a birth time.
+func statTimes(info os.FileInfo) fileTimes {
+	times := fileTimes{Modified: info.ModTime()}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		times.Accessed = time.Unix(st.Atim.Unix())
+		times.Changed = time.Unix(st.Ctim.Unix())
+	}
+	return times
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package main
+
+import (
+	"os"      // Provides the os.FileInfo interface whose Sys() value carries the raw attribute data.
+	"syscall" // Provides syscall.Win32FileAttributeData with the NTFS timestamps.
+	"time"    // For converting FILETIME values into time.Time.
+)
+
+// statTimes extracts the timestamps Windows exposes through the file attribute data.
+// Windows has no inode change time, so Changed stays unset.
+func statTimes(info os.FileInfo) fileTimes {
+	times := fileTimes{Modified: info.ModTime()}
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		times.Accessed = time.Unix(0, data.LastAccessTime.Nanoseconds())
+		if data.CreationTime != (syscall.Filetime{}) {
+			times.Born = time.Unix(0, data.CreationTime.Nanoseconds())
+		}
+	}
+	return times
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"         // For adding the event type to an encoded record.
+	"encoding/json" // For encoding events.
+	"io"            // For writing events to stdout or the log file.
+	"sync"          // For writing events from parallel workers.
+)
+
+// --- NDJSON Streaming ---
+// With '--output ndjson', results are written as one JSON object per line while the command runs
+// instead of one document at the end, so pipelines can process them incrementally and huge result
+// sets don't have to be held for encoding. Every line has a 'type' field:
+//
+//	found    A result record: a file, folder, duplicate set or report row.
+//	deleted  An item that was deleted or moved to the trash.
+//	error    A non-fatal error, with the fields of an error record.
+//	summary  The run summary, always the last line.
+
+// Event types.
+const (
+	eventFound   = "found"
+	eventDeleted = "deleted"
+	eventError   = "error"
+	eventSummary = "summary"
+)
+
+// deletedEvent is the record of a deleted event.
+type deletedEvent struct {
+	Path   string `json:"path"`
+	Action string `json:"action"` // delete or trash.
+	Bytes  int64  `json:"bytes"`
+}
+
+// stream is where events go; w is nil when not streaming.
+var stream struct {
+	sync.Mutex
+	w io.Writer
+}
+
+// streaming reports whether results are streamed as NDJSON.
+func streaming() bool {
+	return config.OutputFormat == "ndjson"
+}
+
+// openStream opens the event stream if --output ndjson is set. It is called once the
+// configuration is loaded, so that events can be written from the first result on.
+func openStream() {
+	if !streaming() {
+		return
+	}
+	w := resultWriter()
+	stream.Lock()
+	defer stream.Unlock()
+	stream.w = w
+}
+
+// closeStream ends the event stream after the summary has been written.
+func closeStream() {
+	stream.Lock()
+	defer stream.Unlock()
+	stream.w = nil
+}
+
+// emitEvent writes a record as one line, with the event type added as its first field.
+// The record must encode as a JSON object, e.g. a map or a struct.
+func emitEvent(eventType string, record interface{}) {
+	if !streaming() {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil || len(data) < 2 || data[0] != '{' {
+		// Not via addError, which emits an event itself.
+		logError("failed to encode %s event: %v", eventType, err)
+		return
+	}
+	typeField, _ := json.Marshal(eventType)
+	var line bytes.Buffer
+	line.WriteString(`{"type":`)
+	line.Write(typeField)
+	if len(data) > 2 {
+		line.WriteByte(',')
+	}
+	line.Write(data[1:])
+	line.WriteByte('\n')
+
+	stream.Lock()
+	defer stream.Unlock()
+	if stream.w != nil {
+		stream.w.Write(line.Bytes())
+	}
+}
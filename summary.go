@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"       // For the exit code of cancelled runs.
+	"crypto/rand"   // For generating run IDs.
+	"encoding/hex"  // For formatting run IDs.
+	"encoding/json" // For writing the summary document.
+	"fmt"           // For error messages.
+	"os"            // For writing the --summary-file.
+	"sync"          // For updating the counters from parallel workers.
+	"time"          // For the start and end time of the run.
+)
+
+// --- Run Summary ---
+// Every run ends with a machine-readable summary: what was run on which paths, what was found
+// and deleted, how many errors occurred and the exit status. In JSON mode it is printed as the
+// final document after the results, and --summary-file writes it to a file for dashboards.
+
+// runSummary is the summary document of a run.
+type runSummary struct {
+	SchemaVersion   int       `json:"schema_version"` // Version of the result and summary schemas.
+	RunID           string    `json:"run_id"`
+	Command         string    `json:"command"`
+	Targets         []string  `json:"targets"`
+	ConfigFile      string    `json:"config_file,omitempty"`
+	Profile         string    `json:"profile,omitempty"`
+	Action          string    `json:"action"` // delete, trash, dry-run or none for read-only commands.
+	Started         time.Time `json:"started"`
+	Finished        time.Time `json:"finished"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	ScannedItems    int64     `json:"scanned_items"`
+	ScannedBytes    int64     `json:"scanned_bytes"`
+	Results         int       `json:"results"`         // Result records reported, e.g. files, folders or duplicate sets.
+	Candidates      int       `json:"candidates"`      // Items selected for deletion.
+	CandidateBytes  int64     `json:"candidate_bytes"` // Their total size, where known.
+	Deleted         int       `json:"deleted"`
+	FreedBytes      int64     `json:"freed_bytes"`
+	Failed          int       `json:"failed"` // Deletions that failed.
+	Errors          int       `json:"errors"`
+	ExitCode        int       `json:"exit_code"`
+	Status          string    `json:"status"`
+	FatalError      string    `json:"fatal_error,omitempty"`
+}
+
+// exitStatuses names the exit codes in the summary.
+var exitStatuses = map[int]string{
+	exitOK:        "ok",
+	exitFatal:     "fatal",
+	exitPartial:   "partial",
+	exitFound:     "found",
+	exitCancelled: "cancelled",
+}
+
+// runState holds what the summary reports, collected while the command runs.
+var runState = struct {
+	sync.Mutex
+	id             string
+	started        time.Time
+	command        string
+	targets        []string
+	readOnly       bool
+	results        int
+	candidates     int
+	candidateBytes int64
+	deleted        int
+	freedBytes     int64
+	failed         int
+}{id: newRunID(), started: time.Now()}
+
+// newRunID returns a random ID that identifies a run in logs and summaries.
+func newRunID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// recordCommand records the command being run and the paths it was given.
+func recordCommand(name string, targets []string, readOnly bool) {
+	runState.Lock()
+	defer runState.Unlock()
+	runState.command, runState.targets, runState.readOnly = name, targets, readOnly
+}
+
+// isReadOnlyCommand reports whether a command never deletes anything.
+func isReadOnlyCommand(name string) bool {
+	return name != "empty" && name != "find"
+}
+
+// recordTargets replaces the recorded paths with the resolved target directories.
+func recordTargets(targets []string) {
+	runState.Lock()
+	defer runState.Unlock()
+	runState.targets = targets
+}
+
+// recordResults counts result records as they are reported.
+func recordResults(n int) {
+	runState.Lock()
+	defer runState.Unlock()
+	runState.results += n
+}
+
+// recordCandidates counts the items selected for deletion.
+func recordCandidates(n int, bytes int64) {
+	runState.Lock()
+	defer runState.Unlock()
+	runState.candidates += n
+	runState.candidateBytes += bytes
+}
+
+// recordDeletion counts the outcome of one deletion.
+func recordDeletion(failed bool, bytes int64) {
+	runState.Lock()
+	defer runState.Unlock()
+	if failed {
+		runState.failed++
+		return
+	}
+	runState.deleted++
+	runState.freedBytes += bytes
+}
+
+// buildRunSummary assembles the summary of the finished run.
+func buildRunSummary(code int, err error) runSummary {
+	runState.Lock()
+	defer runState.Unlock()
+	finished := time.Now()
+	summary := runSummary{
+		SchemaVersion:   resultSchemaVersion,
+		RunID:           runState.id,
+		Command:         runState.command,
+		Targets:         runState.targets,
+		ConfigFile:      configFileUsed,
+		Profile:         config.Profile,
+		Action:          getActionName(),
+		Started:         runState.started,
+		Finished:        finished,
+		DurationSeconds: finished.Sub(runState.started).Seconds(),
+		ScannedItems:    scanTotals.items.Load(),
+		ScannedBytes:    scanTotals.bytes.Load(),
+		Results:         runState.results,
+		Candidates:      runState.candidates,
+		CandidateBytes:  runState.candidateBytes,
+		Deleted:         runState.deleted,
+		FreedBytes:      runState.freedBytes,
+		Failed:          runState.failed,
+		Errors:          len(collectedErrors()),
+		ExitCode:        code,
+		Status:          exitStatuses[code],
+	}
+	switch {
+	case runState.readOnly:
+		summary.Action = "none"
+	case config.DryRun:
+		summary.Action = "dry-run"
+	}
+	if summary.Targets == nil {
+		summary.Targets = []string{}
+	}
+	if err != nil {
+		summary.FatalError = err.Error()
+	}
+	return summary
+}
+
+// finishRun writes the end-of-run reports (--errors-out, the summary in NDJSON and HTML output or
+// the error table in the other table formats, and --summary-file) and returns the exit code of the
+// run.
+func finishRun(ctx context.Context, err error) int {
+	// The errors file is written even if the command failed, since that's when it's needed most.
+	if config.ErrorsOut != "" {
+		addError(writeErrorsOut(config.ErrorsOut))
+	}
+	code := exitCode(ctx, err)
+	summary := buildRunSummary(code, err)
+
+	if streaming() {
+		// Events were written as they happened; the summary ends the stream.
+		openStream()
+		emitEvent(eventSummary, summary)
+	} else if writer := resultWriter(); writer != nil {
+		records := collectedErrors()
+		var errorRows [][]string
+		for _, r := range records {
+			errorRows = append(errorRows, r.csvRecord())
+		}
+		switch config.OutputFormat {
+		case "json", "print0", "template":
+			// Nothing but the results, so that -o json stays a single array. The summary is
+			// available with --summary-file and the errors with --errors-out.
+		case "html":
+			writeTable(writer, "Summary", []string{"field", "value"}, recordRows(summary))
+			if len(records) > 0 {
+				writeTable(writer, "Errors", errorHeaders, errorRows)
+			}
+		default:
+			// A second table after a blank line, if there were errors.
+			if len(records) > 0 {
+				fmt.Fprintln(writer)
+				if err := writeTable(writer, "Errors", errorHeaders, errorRows); err != nil {
+					logError("failed to write error table: %v", err)
+				}
+			}
+		}
+	}
+	closeStream()
+	closeResultWriter()
+
+	if config.SummaryFile != "" {
+		if err := writeSummaryFile(config.SummaryFile, summary); err != nil {
+			logError("%v", err)
+			if code == exitOK || code == exitFound {
+				code = exitPartial
+			}
+		}
+	}
+	return code
+}
+
+// writeSummaryFile writes the summary as a JSON document.
+func writeSummaryFile(path string, summary runSummary) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create summary file: %w", err)
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(summary); err != nil {
+		return fmt.Errorf("could not write summary file: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"         // For rendering a line before writing it.
+	"fmt"           // For error messages.
+	"io"            // For writing the lines.
+	"reflect"       // For checking the template against the record type.
+	"strings"       // For unescaping the format.
+	"text/template" // For --format.
+	"time"          // For the date helpers.
+)
+
+// --- Output Templates ---
+// --format renders every result record with a text/template, one line per record, e.g.
+//
+//	cleanup find --older-than 365d --format '{{.Path}}\t{{.Size | bytes}}\t{{.ModTime | date}}'
+//
+// The fields are those of the result types in results.go, by their Go names or by their JSON names
+// as in --fields and 'cleanup schema' ({{.modified}} is the same as {{.ModTime}}). As with 'find -printf', the escapes \t, \n and \\ can be written as such,
+// since shells don't expand them in quotes.
+
+// resultTemplate is the parsed --format template, or nil.
+var resultTemplate *template.Template
+
+// templateFuncs are the helper functions available in --format templates.
+var templateFuncs = template.FuncMap{
+	// bytes formats a size, e.g. 1.5 GB.
+	"bytes": formatBytes,
+	// date formats a time as local date and time, e.g. 2025-01-31 14:05:00.
+	"date": func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	// datefmt formats a time with a Go layout, e.g. {{.ModTime | datefmt "2006-01-02"}}.
+	"datefmt": func(layout string, t time.Time) string { return t.Format(layout) },
+}
+
+// checkFormatFlag parses --format and checks that it only uses fields the command's results have.
+func checkFormatFlag(command string) error {
+	resultTemplate = nil
+	if config.Format == "" {
+		return nil
+	}
+	format := strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n").Replace(config.Format)
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	tmpl, err := template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid value for --format: %w", err)
+	}
+	// A trial run on an empty record catches misspelled fields before the scan, not after it.
+	if schema := commandSchema(command); schema != nil && schema.row != nil {
+		if err := tmpl.Execute(io.Discard, templateData(reflect.Zero(schema.row))); err != nil {
+			return fmt.Errorf("invalid value for --format: %w. Available fields: [%s]", err, strings.Join(templateFields(schema.row), ", "))
+		}
+	}
+	resultTemplate = tmpl
+	return nil
+}
+
+// templateFields lists the fields of a record type as they are written in a template.
+func templateFields(t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			fields = append(fields, fmt.Sprintf(".%s (.%s)", t.Field(i).Name, name))
+		}
+	}
+	return fields
+}
+
+// templateData returns the fields of a record by their Go and JSON names, keeping their types so
+// that sizes and times can be passed to the helpers.
+func templateData(record reflect.Value) map[string]interface{} {
+	data := make(map[string]interface{})
+	for i := 0; i < record.NumField(); i++ {
+		field := record.Type().Field(i)
+		if name := jsonName(field); name != "" {
+			data[field.Name] = record.Field(i).Interface()
+			data[name] = data[field.Name]
+		}
+	}
+	return data
+}
+
+// outputTemplate writes a slice of result records with the --format template, one line each.
+func outputTemplate(rows reflect.Value, writer io.Writer) {
+	if resultTemplate == nil {
+		return
+	}
+	var line bytes.Buffer
+	for i := 0; i < rows.Len(); i++ {
+		line.Reset()
+		if err := resultTemplate.Execute(&line, templateData(rows.Index(i))); err != nil {
+			addError(fmt.Errorf("failed to format result: %w", err))
+			return
+		}
+		writer.Write(line.Bytes())
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFormatExampleWorksForEveryCommand checks the --format example of the README, with Go and
+// with JSON field names, against the results of every command that has a size and a modification time.
+func TestFormatExampleWorksForEveryCommand(t *testing.T) {
+	defer func(old Config) { config = old }(config)
+	for _, format := range []string{
+		`{{.Path}}\t{{.Size | bytes}}\t{{.ModTime | date}}`,
+		`{{.path}}\t{{.size | bytes}}\t{{.modified | date}}`,
+	} {
+		config.Format = format
+		for _, command := range []string{"empty", "find", "find-duplicates", "large"} {
+			if err := checkFormatFlag(command); err != nil {
+				t.Errorf("%s with %s: %v", command, format, err)
+			}
+		}
+	}
+
+	config.Format = `{{.Paht}}`
+	if err := checkFormatFlag("find"); err == nil || !strings.Contains(err.Error(), ".Path (.path)") {
+		t.Errorf("a misspelled field = %v, want an error listing the fields", err)
+	}
+}
+
+func TestOutputTemplate(t *testing.T) {
+	defer func(old Config) { config = old }(config)
+	config.Format = `{{.Path}}\t{{.size_formatted}}\t{{.ModTime | datefmt "2006-01-02"}}`
+	if err := checkFormatFlag("large"); err != nil {
+		t.Fatal(err)
+	}
+	rows := []largeDirResult{
+		{Path: "/srv/a", SizeFormatted: "1.5 GB", ModTime: time.Date(2025, 1, 31, 14, 5, 0, 0, time.Local)},
+		{Path: "/srv/b", SizeFormatted: "10 MB", ModTime: time.Date(2024, 12, 1, 0, 0, 0, 0, time.Local)},
+	}
+	var out bytes.Buffer
+	outputTemplate(reflect.ValueOf(rows), &out)
+	if want := "/srv/a\t1.5 GB\t2025-01-31\n/srv/b\t10 MB\t2024-12-01\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"errors"        // For creating simple parse errors.
+	"fmt"           // For building parse error messages.
+	"os"            // Provides the os.FileInfo interface the expressions are evaluated against.
+	"path/filepath" // For extracting file names and extensions.
+	"regexp"        // For the '~' and '!~' match operators.
+	"sort"          // For listing the known fields in a stable order.
+	"strconv"       // For parsing plain numeric literals such as UIDs.
+	"strings"       // For string manipulation while tokenizing and comparing.
+	"time"          // For age and date comparisons.
+)
+
+// --- Filter Expression Language ---
+// A --where expression combines comparisons with &&, || and !, for example:
+//
+//	size > 100MB && (ext == "log" || name ~ "^core\.") && age > 30d && owner == "ci"
+//
+// The expression is parsed once into a tree of whereNode values and then evaluated for every file.
+
+// whereFields maps each field name to the kind of value it holds.
+// The kind decides how the literal on the right-hand side is parsed and which operators are allowed.
+var whereFields = map[string]string{
+	"name":     "string",   // File name without the directory.
+	"ext":      "string",   // Extension without the leading dot, lowercased.
+	"path":     "string",   // Full path.
+	"owner":    "string",   // Name of the owning user; == and != with a number compare the UID, as --owner does.
+	"group":    "string",   // Name of the owning group; == and != with a number compare the GID, as --group does.
+	"uid":      "number",   // Numeric ID of the owning user.
+	"gid":      "number",   // Numeric ID of the owning group.
+	"size":     "size",     // Size in bytes; literals accept units like 100MB.
+	"age":      "duration", // Time since the timestamp selected by --age-by; literals like 30d.
+	"modified": "date",     // Modification time; literals like 2025-01-01.
+}
+
+// whereFile is the file an expression is evaluated against.
+type whereFile struct {
+	path string
+	info os.FileInfo
+	rc   *runContext
+}
+
+// whereNode is a node of a parsed --where expression.
+type whereNode interface {
+	eval(f *whereFile) bool
+}
+
+type whereAnd struct{ left, right whereNode }
+type whereOr struct{ left, right whereNode }
+type whereNot struct{ expr whereNode }
+
+func (n whereAnd) eval(f *whereFile) bool { return n.left.eval(f) && n.right.eval(f) }
+func (n whereOr) eval(f *whereFile) bool  { return n.left.eval(f) || n.right.eval(f) }
+func (n whereNot) eval(f *whereFile) bool { return !n.expr.eval(f) }
+
+// whereStringCompare compares a string field with ==, != or a regular expression.
+type whereStringCompare struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (n whereStringCompare) eval(f *whereFile) bool {
+	actual, ok := f.stringField(n.field)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "==":
+		return actual == n.value
+	case "!=":
+		return actual != n.value
+	case "~":
+		return n.re.MatchString(actual)
+	case "!~":
+		return !n.re.MatchString(actual)
+	}
+	return false
+}
+
+// whereNumberCompare compares a numeric field (IDs, size in bytes, age or date in nanoseconds).
+type whereNumberCompare struct {
+	field string
+	op    string
+	value int64
+}
+
+func (n whereNumberCompare) eval(f *whereFile) bool {
+	actual, ok := f.numberField(n.field)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "==":
+		return actual == n.value
+	case "!=":
+		return actual != n.value
+	case "<":
+		return actual < n.value
+	case "<=":
+		return actual <= n.value
+	case ">":
+		return actual > n.value
+	case ">=":
+		return actual >= n.value
+	}
+	return false
+}
+
+// stringField returns the value of a string field, or false if it is unavailable for this file.
+func (f *whereFile) stringField(field string) (string, bool) {
+	switch field {
+	case "name":
+		return filepath.Base(f.path), true
+	case "ext":
+		return strings.ToLower(strings.TrimPrefix(filepath.Ext(f.path), ".")), true
+	case "path":
+		return filepath.ToSlash(f.path), true
+	case "owner", "group":
+		uid, gid, ok := fileOwnership(f.info)
+		if !ok {
+			return "", false
+		}
+		if field == "owner" {
+			return lookupUserName(uid), true
+		}
+		return lookupGroupName(gid), true
+	}
+	return "", false
+}
+
+// numberField returns the value of a numeric field, or false if it is unavailable for this file.
+func (f *whereFile) numberField(field string) (int64, bool) {
+	switch field {
+	case "size":
+		return f.info.Size(), true
+	case "age":
+		t := f.rc.fileTime(f.path, f.info)
+		if t.IsZero() {
+			return 0, false
+		}
+		return int64(time.Since(t)), true
+	case "modified":
+		return f.info.ModTime().UnixNano(), true
+	case "uid", "gid":
+		uid, gid, ok := fileOwnership(f.info)
+		if field == "uid" {
+			return int64(uid), ok
+		}
+		return int64(gid), ok
+	}
+	return 0, false
+}
+
+// --- Tokenizer ---
+
+// whereToken is a lexical token of a --where expression.
+// kind is "op" for operators and parentheses, "string" for quoted literals and "word" for everything else.
+type whereToken struct {
+	kind string
+	text string
+	pos  int
+}
+
+// whereOperators lists all operators, longest first so that "<=" is not read as "<" followed by "=".
+var whereOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "!~", "(", ")", "!", "<", ">", "~"}
+
+func tokenizeWhere(expr string) ([]whereToken, error) {
+	var tokens []whereToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			i++
+			continue
+		}
+
+		if c == '"' || c == '\'' {
+			// Quoted strings only treat \" (or \') and \\ as escapes.
+			// Every other backslash is kept, so regular expressions like "^core\." work unchanged.
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(expr) && expr[j] != c; j++ {
+				if expr[j] == '\\' && j+1 < len(expr) && (expr[j+1] == c || expr[j+1] == '\\') {
+					j++
+				}
+				sb.WriteByte(expr[j])
+			}
+			if j >= len(expr) {
+				return nil, fmt.Errorf("unterminated string starting at position %d", i+1)
+			}
+			tokens = append(tokens, whereToken{kind: "string", text: sb.String(), pos: i + 1})
+			i = j + 1
+			continue
+		}
+
+		matchedOp := ""
+		for _, op := range whereOperators {
+			if strings.HasPrefix(expr[i:], op) {
+				matchedOp = op
+				break
+			}
+		}
+		if matchedOp != "" {
+			tokens = append(tokens, whereToken{kind: "op", text: matchedOp, pos: i + 1})
+			i += len(matchedOp)
+			continue
+		}
+
+		j := i
+		for j < len(expr) && !strings.ContainsRune(" \t\n\r\"'()&|=!<>~", rune(expr[j])) {
+			j++
+		}
+		if j == i {
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i+1)
+		}
+		tokens = append(tokens, whereToken{kind: "word", text: expr[i:j], pos: i + 1})
+		i = j
+	}
+	return tokens, nil
+}
+
+// --- Parser ---
+// The grammar, from lowest to highest precedence:
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" or ")" | comparison
+//	comparison = FIELD OPERATOR VALUE
+
+type whereParser struct {
+	tokens []whereToken
+	pos    int
+}
+
+// parseWhere parses a --where expression into an evaluable tree.
+func parseWhere(expr string) (whereNode, error) {
+	tokens, err := tokenizeWhere(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("expression cannot be empty")
+	}
+	p := &whereParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return node, nil
+}
+
+func (p *whereParser) peek() (whereToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return whereToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *whereParser) next() (whereToken, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return whereToken{}, errors.New("unexpected end of expression")
+	}
+	p.pos++
+	return tok, nil
+}
+
+// acceptOp consumes the next token if it is the given operator.
+func (p *whereParser) acceptOp(op string) bool {
+	if tok, ok := p.peek(); ok && tok.kind == "op" && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) parseOr() (whereNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = whereOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseAnd() (whereNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = whereAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseUnary() (whereNode, error) {
+	if p.acceptOp("!") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return whereNot{expr}, nil
+	}
+	if p.acceptOp("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptOp(")") {
+			if tok, ok := p.peek(); ok {
+				return nil, fmt.Errorf("expected ')' at position %d, found %q", tok.pos, tok.text)
+			}
+			return nil, errors.New("missing closing ')'")
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *whereParser) parseComparison() (whereNode, error) {
+	fieldTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	field := strings.ToLower(fieldTok.text)
+	kind, known := whereFields[field]
+	if fieldTok.kind != "word" || !known {
+		return nil, fmt.Errorf("unknown field %q at position %d. Allowed fields are: %s", fieldTok.text, fieldTok.pos, strings.Join(whereFieldNames(), ", "))
+	}
+
+	opTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := opTok.text
+	if opTok.kind != "op" || !contains([]string{"==", "!=", "<", "<=", ">", ">=", "~", "!~"}, op) {
+		return nil, fmt.Errorf("expected a comparison operator after %q at position %d, found %q", fieldTok.text, opTok.pos, opTok.text)
+	}
+
+	valueTok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if valueTok.kind == "op" {
+		return nil, fmt.Errorf("expected a value at position %d, found %q", valueTok.pos, valueTok.text)
+	}
+	value := valueTok.text
+
+	if (field == "owner" || field == "group") && (op == "==" || op == "!=") {
+		if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+			// owner == 1000 means the UID, just like --owner 1000.
+			idField := map[string]string{"owner": "uid", "group": "gid"}[field]
+			return whereNumberCompare{field: idField, op: op, value: int64(id)}, nil
+		}
+	}
+	if kind == "string" {
+		node := whereStringCompare{field: field, op: op, value: value}
+		switch op {
+		case "==", "!=":
+		case "~", "!~":
+			if node.re, err = regexp.Compile(value); err != nil {
+				return nil, fmt.Errorf("invalid regular expression for %q at position %d: %w", field, valueTok.pos, err)
+			}
+		default:
+			return nil, fmt.Errorf("operator %q is not supported for text field %q (use ==, !=, ~ or !~)", op, field)
+		}
+		return node, nil
+	}
+
+	if op == "~" || op == "!~" {
+		return nil, fmt.Errorf("operator %q is only supported for text fields, not %q", op, field)
+	}
+	node := whereNumberCompare{field: field, op: op}
+	switch kind {
+	case "number":
+		node.value, err = strconv.ParseInt(value, 10, 64)
+	case "size":
+		node.value, err = parseSize(value)
+	case "duration":
+		var dur time.Duration
+		dur, err = parseDuration(value)
+		node.value = int64(dur)
+	case "date":
+		var date time.Time
+		date, err = parseDate(value)
+		node.value = date.UnixNano()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %q at position %d: %w", field, valueTok.pos, err)
+	}
+	return node, nil
+}
+
+// whereUsesField reports whether an expression compares the given field.
+func whereUsesField(node whereNode, field string) bool {
+	switch n := node.(type) {
+	case whereAnd:
+		return whereUsesField(n.left, field) || whereUsesField(n.right, field)
+	case whereOr:
+		return whereUsesField(n.left, field) || whereUsesField(n.right, field)
+	case whereNot:
+		return whereUsesField(n.expr, field)
+	case whereStringCompare:
+		return n.field == field
+	case whereNumberCompare:
+		return n.field == field
+	}
+	return false
+}
+
+func whereFieldNames() []string {
+	names := make([]string, 0, len(whereFields))
+	for name := range whereFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
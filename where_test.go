@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenizeWhere(t *testing.T) {
+	tokens, err := tokenizeWhere(`size>=10MB && !(name ~ "^core\." || ext != 'l\'og')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tok := range tokens {
+		got = append(got, tok.kind+":"+tok.text)
+	}
+	want := []string{
+		"word:size", "op:>=", "word:10MB", "op:&&", "op:!", "op:(",
+		"word:name", "op:~", `string:^core\.`, "op:||",
+		"word:ext", "op:!=", "string:l'og", "op:)",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("tokens = %q, want %q", got, want)
+	}
+}
+
+func TestTokenizeWhereErrors(t *testing.T) {
+	for _, expr := range []string{`name == "x`, `size > 1MB & age > 1d`, `name = x`} {
+		if _, err := tokenizeWhere(expr); err == nil {
+			t.Errorf("tokenizeWhere(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestParseWhereErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"size >",
+		"colour == red",
+		"size ~ 10MB",
+		"name < x",
+		"(size > 1MB",
+		"size > 1MB)",
+		"size > lots",
+		"age > 3 weeks",
+		"size > 1MB &&",
+		`name ~ "("`,
+	} {
+		if _, err := parseWhere(expr); err == nil {
+			t.Errorf("parseWhere(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestWhereEval(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int, age time.Duration) *whereFile {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modified := time.Now().Add(-age)
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &whereFile{path: path, info: info, rc: &runContext{ageBy: "mtime"}}
+	}
+	oldLog := write("app.LOG", 2048, 40*24*time.Hour)
+	newLog := write("new.log", 10, time.Hour)
+	core := write("core.1234", 4096, 40*24*time.Hour)
+
+	tests := []struct {
+		expr string
+		want map[*whereFile]bool
+	}{
+		{`ext == "log"`, map[*whereFile]bool{oldLog: true, newLog: true, core: false}},
+		{`size > 1KB && age > 30d`, map[*whereFile]bool{oldLog: true, newLog: false, core: true}},
+		{`name ~ "^core\." || size < 100`, map[*whereFile]bool{oldLog: false, newLog: true, core: true}},
+		{`!(ext == "log") && age >= 1d`, map[*whereFile]bool{oldLog: false, newLog: false, core: true}},
+		{`ext == "log" || name ~ "^core" && size > 1MB`, map[*whereFile]bool{oldLog: true, newLog: true, core: false}},
+		{`modified < 2000-01-01`, map[*whereFile]bool{oldLog: false, newLog: false, core: false}},
+		{`name !~ "log$" && size != 4096`, map[*whereFile]bool{oldLog: true, newLog: false, core: false}},
+	}
+	for _, tt := range tests {
+		expr, err := parseWhere(tt.expr)
+		if err != nil {
+			t.Fatalf("parseWhere(%q): %v", tt.expr, err)
+		}
+		for file, want := range tt.want {
+			if got := expr.eval(file); got != want {
+				t.Errorf("%q on %s = %v, want %v", tt.expr, filepath.Base(file.path), got, want)
+			}
+		}
+	}
+}
+
+func TestWhereUsesField(t *testing.T) {
+	expr, err := parseWhere(`size > 1MB || !(name == "x" && age > 1d)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !whereUsesField(expr, "age") || whereUsesField(expr, "modified") {
+		t.Error("whereUsesField misreports the fields of the expression")
+	}
+}
+
+func TestWhereOwnerByID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("files have no UID on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "mine")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := &whereFile{path: path, info: info, rc: &runContext{}}
+	for expr, want := range map[string]bool{
+		fmt.Sprintf("owner == %d", os.Getuid()):      true,
+		fmt.Sprintf("owner != %d", os.Getuid()):      false,
+		fmt.Sprintf(`group == "%d"`, os.Getgid()):    true,
+		fmt.Sprintf("owner == %d", os.Getuid()+1):    false,
+		fmt.Sprintf("owner ~ \"^%d$\"", os.Getuid()): false, // Regular expressions still match the name.
+	} {
+		node, err := parseWhere(expr)
+		if err != nil {
+			t.Fatalf("parseWhere(%q): %v", expr, err)
+		}
+		if got := node.eval(file); got != want {
+			t.Errorf("%s = %v, want %v", expr, got, want)
+		}
+	}
+}
+
+// TestWhereNarrowsMatchAny checks that --where is required on top of the --match any criteria.
+func TestWhereNarrowsMatchAny(t *testing.T) {
+	isOld := fileCriterion{"older-than", func(path string, info os.FileInfo) bool { return path != "new.txt" }}
+	isLog := fileCriterion{"where", func(path string, info os.FileInfo) bool { return strings.HasSuffix(path, ".log") }}
+	rc := &runContext{criteria: []fileCriterion{isOld}, filters: []fileCriterion{isLog}}
+
+	for path, want := range map[string]bool{"x.log": true, "keep.txt": false, "new.txt": false} {
+		if got := rc.matchesCriteria(path, nil); got != want {
+			t.Errorf("matchesCriteria(%s) = %v, want %v", path, got, want)
+		}
+	}
+
+	rc.criteria = nil
+	if !rc.matchesCriteria("x.log", nil) || rc.matchesCriteria("keep.txt", nil) {
+		t.Error("a run with only --where must select exactly the files it matches")
+	}
+}